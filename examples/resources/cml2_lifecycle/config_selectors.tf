@@ -0,0 +1,21 @@
+# configs selected by glob pattern or tag
+#
+# Keys which are not node labels select several nodes, either by a glob pattern
+# on the node label or by a tag. The configuration is a template which is
+# rendered for each selected node. Go templates use {{ }} which, unlike ${ },
+# does not need to be escaped in Terraform strings.
+
+resource "cml2_lifecycle" "this" {
+  topology = file("fabric.yaml")
+  configs = {
+    # a node label takes precedence over patterns and tags
+    "spine-1" = file("spine-1.cfg")
+    # all leaf nodes share a baseline
+    "leaf-*" = <<-EOT
+    hostname {{ .Hostname }}
+    ! tags: {{ join .Tags "," }}
+    EOT
+    # remaining edge nodes
+    "tag:edge" = "hostname {{ .Hostname }}"
+  }
+}
@@ -0,0 +1,22 @@
+# configuration templates with variables
+#
+# config_templates are always rendered, also for keys which are node labels.
+# Besides the node label, hostname and tags, templates can refer to the node
+# ID, the interface labels in slot order, the lab and to config_vars.
+
+resource "cml2_lifecycle" "this" {
+  topology = file("fabric.yaml")
+  config_vars = {
+    domain = "lab.example.com"
+  }
+  config_templates = {
+    "tag:ios" = <<-EOT
+    hostname {{ .Hostname }}
+    ip domain name {{ .Vars.domain }}
+    {{- range .Interfaces }}
+    interface {{ . }}
+     description {{ $.LabTitle }}
+    {{- end }}
+    EOT
+  }
+}
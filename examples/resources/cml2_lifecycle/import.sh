@@ -0,0 +1,7 @@
+# a lifecycle can be imported by lab title, by lab ID or using the plain lab ID
+# import is only supported for configurations with lab_id, the topology of
+# an imported lab is not known. Configuring a topology instead plans a replace
+# which creates a new lab from it, the imported lab is left as is.
+terraform import cml2_lifecycle.this "title:Instructor Lab"
+terraform import cml2_lifecycle.this "lab_id:b1c5cbbc-3e0c-4e43-8e11-2ad1b7c7a5ff"
+terraform import cml2_lifecycle.this b1c5cbbc-3e0c-4e43-8e11-2ad1b7c7a5ff
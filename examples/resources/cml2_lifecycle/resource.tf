@@ -147,3 +147,8 @@ output "r1_ip_address" {
     )
   )
 }
+
+# the same, using the flattened management addresses
+output "r1_management_ip" {
+  value = lookup(cml2_lifecycle.top.management_ip, cml2_node.r1.label, "undefined")
+}
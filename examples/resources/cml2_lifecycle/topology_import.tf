@@ -53,7 +53,7 @@ resource "cml2_lab" "example" {
   timeouts = {
     create = "20h"
     update = "1h30m"
-    delete = "20m" # currently unused
+    delete = "20m" # bounds the complete teardown on destroy
   }
 
 }
@@ -0,0 +1,2 @@
+# the ID is the lab ID and the node ID, separated by a slash
+terraform import cml2_node_state.r41 a6c124ca-1268-4de1-8bb0-6bb01e7764af/2aea8bd1-2e9c-4d5d-a3ab-64d4cd84b2a8
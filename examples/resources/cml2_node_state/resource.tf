@@ -0,0 +1,16 @@
+# a node added to a running lab, only this node is started
+resource "cml2_node" "r41" {
+  lab_id         = cml2_lab.lab.id
+  label          = "r41"
+  nodedefinition = "iosv"
+}
+
+resource "cml2_node_state" "r41" {
+  lab_id  = cml2_lab.lab.id
+  node_id = cml2_node.r41.id
+  state   = "STARTED"
+
+  timeouts = {
+    create = "20m"
+  }
+}
@@ -0,0 +1,33 @@
+locals {
+  hosts = ["host-1", "host-2", "host-3"]
+}
+
+resource "cml2_lab" "lab" {
+  title = "topology-example"
+}
+
+# a switch with one host per port, adding a host only adds its node and link
+resource "cml2_topology" "hosts" {
+  lab_id = cml2_lab.lab.id
+  nodes = merge(
+    {
+      sw = { nodedefinition = "unmanaged_switch", x = 0, y = 0 }
+    },
+    {
+      for idx, name in local.hosts : name => {
+        nodedefinition = "alpine"
+        x              = (idx - 1) * 120
+        y              = 160
+        tags           = ["hosts"]
+      }
+    }
+  )
+  links = [
+    for idx, name in local.hosts : {
+      node_a = "sw"
+      slot_a = idx
+      node_b = name
+      slot_b = 0
+    }
+  ]
+}
@@ -0,0 +1,53 @@
+package cmlschema
+
+import (
+	"strings"
+	"time"
+)
+
+// autoStopMarker prefixes the line in the lab notes which records the auto
+// stop deadline of a lab, see the lifecycle `auto_stop_after` attribute.
+const autoStopMarker = "cml2-auto-stop-deadline: "
+
+// AutoStopDeadline returns the auto stop deadline recorded in the lab notes.
+func AutoStopDeadline(notes string) (time.Time, bool) {
+	for _, line := range strings.Split(notes, "\n") {
+		value, ok := strings.CutPrefix(strings.TrimSpace(line), autoStopMarker)
+		if !ok {
+			continue
+		}
+		deadline, err := time.Parse(time.RFC3339, strings.TrimSpace(value))
+		if err != nil {
+			return time.Time{}, false
+		}
+		return deadline, true
+	}
+	return time.Time{}, false
+}
+
+// StripAutoStopDeadline returns the lab notes without the auto stop deadline
+// line.
+func StripAutoStopDeadline(notes string) string {
+	if !strings.Contains(notes, autoStopMarker) {
+		return notes
+	}
+	lines := []string{}
+	for _, line := range strings.Split(notes, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), autoStopMarker) {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// WithAutoStopDeadline returns the lab notes with the auto stop deadline line
+// set to the given deadline, the line is appended to the notes. Stripping the
+// line again returns the original notes.
+func WithAutoStopDeadline(notes string, deadline time.Time) string {
+	line := autoStopMarker + deadline.UTC().Format(time.RFC3339)
+	notes = StripAutoStopDeadline(notes)
+	if notes == "" {
+		return line
+	}
+	return notes + "\n" + line
+}
@@ -0,0 +1,39 @@
+package cmlschema_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+)
+
+func TestAutoStopDeadline(t *testing.T) {
+	deadline := time.Date(2026, 10, 19, 18, 0, 0, 0, time.UTC)
+
+	_, ok := cmlschema.AutoStopDeadline("some notes")
+	assert.False(t, ok)
+
+	notes := cmlschema.WithAutoStopDeadline("some notes", deadline)
+	assert.Equal(t, "some notes\ncml2-auto-stop-deadline: 2026-10-19T18:00:00Z", notes)
+	got, ok := cmlschema.AutoStopDeadline(notes)
+	assert.True(t, ok)
+	assert.True(t, deadline.Equal(got))
+	assert.Equal(t, "some notes", cmlschema.StripAutoStopDeadline(notes))
+
+	// replaced, not appended again
+	later := deadline.Add(time.Hour)
+	notes = cmlschema.WithAutoStopDeadline(notes, later)
+	assert.Equal(t, "some notes\ncml2-auto-stop-deadline: 2026-10-19T19:00:00Z", notes)
+
+	assert.Equal(t, "cml2-auto-stop-deadline: 2026-10-19T18:00:00Z", cmlschema.WithAutoStopDeadline("", deadline))
+	// notes round trip, including trailing newlines
+	for _, original := range []string{"", "a", "a\n", "a\n\nb\n"} {
+		assert.Equal(t, original, cmlschema.StripAutoStopDeadline(cmlschema.WithAutoStopDeadline(original, deadline)))
+	}
+	assert.Equal(t, "", cmlschema.StripAutoStopDeadline("cml2-auto-stop-deadline: 2026-10-19T18:00:00Z"))
+
+	_, ok = cmlschema.AutoStopDeadline("cml2-auto-stop-deadline: tomorrow")
+	assert.False(t, ok)
+}
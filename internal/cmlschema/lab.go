@@ -214,7 +214,7 @@ func NewLab(ctx context.Context, lab *models.Lab, diags *diag.Diagnostics) attr.
 		Description: types.StringValue(lab.Description),
 		NodeCount:   types.Int64Value(int64(lab.NodeCount)),
 		LinkCount:   types.Int64Value(int64(lab.LinkCount)),
-		Notes:       types.StringValue(lab.Notes),
+		Notes:       types.StringValue(StripAutoStopDeadline(lab.Notes)),
 		Groups:      groups,
 		NodeStaging: nodeStaging,
 	}
@@ -0,0 +1,51 @@
+package cmlschema
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Layout modes, see the lifecycle layout attribute.
+const (
+	LayoutModeGrid         = "grid"
+	LayoutModeHierarchical = "hierarchical"
+	LayoutModeForce        = "force"
+)
+
+// LayoutModel is the Terraform representation of the automatic node layout.
+type LayoutModel struct {
+	Mode    types.String `tfsdk:"mode"`
+	Spacing types.Int64  `tfsdk:"spacing"`
+	Tiers   types.List   `tfsdk:"tiers"`
+}
+
+func lifecycleLayout() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Automatic layout of the lab nodes, computed from the link graph whenever the lifecycle is created or updated. Only nodes without coordinates are moved: nodes of the `topology` without `x` and `y`, other nodes when they are at `x = 0` and `y = 0` (where nodes created without `x` and `y` are placed). Each node is placed once, a node which is moved back to the origin by its own configuration keeps that position. All other nodes keep their position and are taken into account. Nodes which are not yet part of the lifecycle state are placed on the next apply.",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"mode": schema.StringAttribute{
+				MarkdownDescription: "Layout mode. `grid` places the nodes on a grid with connected nodes next to each other, `hierarchical` places the nodes in rows (see `tiers`), `force` runs a force-directed simulation where linked nodes attract and all nodes repel each other.",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(LayoutModeGrid, LayoutModeHierarchical, LayoutModeForce),
+				},
+			},
+			"spacing": schema.Int64Attribute{
+				MarkdownDescription: "Distance between nodes on the canvas. Defaults to `120`.",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(10),
+				},
+			},
+			"tiers": schema.ListAttribute{
+				MarkdownDescription: "Ordered list of node tags for the `hierarchical` mode, the first tier is the top row. A node goes to the row of the first tier matching one of its tags, nodes without a matching tag go to the bottom row. If not set, the row of a node is its distance (in links) from the best connected node.",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+		},
+	}
+}
@@ -2,6 +2,7 @@
 package cmlschema
 
 import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
@@ -15,21 +16,49 @@ import (
 
 // LabLifecycleModel is the Terraform representation of the lifecycle resource state.
 type LabLifecycleModel struct {
-	ID             types.String `tfsdk:"id"`
-	LabID          types.String `tfsdk:"lab_id"`
-	Topology       types.String `tfsdk:"topology"`
-	Wait           types.Bool   `tfsdk:"wait"`
-	State          types.String `tfsdk:"state"`
-	Booted         types.Bool   `tfsdk:"booted"`
-	Nodes          types.Map    `tfsdk:"nodes"`
-	UpdateTriggers types.Map    `tfsdk:"update_triggers"`
-	Configs        types.Map    `tfsdk:"configs"`
-	NamedConfigs   types.Map    `tfsdk:"named_configs"`
-	Staging        types.Object `tfsdk:"staging"`
-	Timeouts       types.Object `tfsdk:"timeouts"`
-	Elements       types.List   `tfsdk:"elements"`
+	ID               types.String `tfsdk:"id"`
+	LabID            types.String `tfsdk:"lab_id"`
+	Topology         types.String `tfsdk:"topology"`
+	Wait             types.Bool   `tfsdk:"wait"`
+	State            types.String `tfsdk:"state"`
+	Booted           types.Bool   `tfsdk:"booted"`
+	Nodes            types.Map    `tfsdk:"nodes"`
+	UpdateTriggers   types.Map    `tfsdk:"update_triggers"`
+	RestartTriggers  types.Map    `tfsdk:"restart_triggers"`
+	RestartWipe      types.Bool   `tfsdk:"restart_wipe"`
+	AllowWipe        types.Bool   `tfsdk:"allow_wipe"`
+	AutoStopAfter    types.String `tfsdk:"auto_stop_after"`
+	AutoStopDeadline types.String `tfsdk:"auto_stop_deadline"`
+	Configs          types.Map    `tfsdk:"configs"`
+	NamedConfigs     types.Map    `tfsdk:"named_configs"`
+	ConfigTemplates  types.Map    `tfsdk:"config_templates"`
+	ConfigVars       types.Map    `tfsdk:"config_vars"`
+	Staging          types.Object `tfsdk:"staging"`
+	Layout           types.Object `tfsdk:"layout"`
+	Timeouts         types.Object `tfsdk:"timeouts"`
+	Elements         types.List   `tfsdk:"elements"`
+	OnFailure        types.String `tfsdk:"on_failure"`
+
+	Addresses           types.Map    `tfsdk:"addresses"`
+	ManagementIP        types.Map    `tfsdk:"management_ip"`
+	ManagementInterface types.String `tfsdk:"management_interface"`
+	WaitForAddresses    types.Object `tfsdk:"wait_for_addresses"`
 }
 
+// Lifecycle create failure policies, see the on_failure attribute.
+const (
+	OnFailureKeep    = "keep"
+	OnFailureStop    = "stop"
+	OnFailureDestroy = "destroy"
+)
+
+// Lifecycle staging modes, see the staging mode attribute.
+const (
+	StagingModeAuto   = "auto"
+	StagingModeServer = "server"
+	StagingModeClient = "client"
+)
+
 // Lifecycle returns the schema for the lifecycle resource.
 func Lifecycle() map[string]schema.Attribute {
 	return map[string]schema.Attribute{
@@ -52,12 +81,9 @@ func Lifecycle() map[string]schema.Attribute {
 		// topology is marked as sensitive mostly b/c lengthy topology
 		// YAML clutters the output.
 		"topology": schema.StringAttribute{
-			MarkdownDescription: "The topology to start, must be valid YAML. Can't be configured if the lab `id` is configured.",
+			MarkdownDescription: "The topology to start, must be valid YAML. Can't be configured if the lab `id` is configured. The topology is validated at plan time (schema version, node labels, links as well as node and image definitions). Changes are applied in place where possible (adding, removing and updating nodes, links and annotations), otherwise the lab is replaced.",
 			Optional:            true,
 			Sensitive:           true,
-			PlanModifiers: []planmodifier.String{
-				stringplanmodifier.RequiresReplace(),
-			},
 		},
 		"wait": schema.BoolAttribute{
 			MarkdownDescription: "If set to `true` then wait until the lab has completely `BOOTED`.",
@@ -87,6 +113,25 @@ func Lifecycle() map[string]schema.Attribute {
 			// Do not pin computed nodes to prior state values.
 			// The simulator may update coordinates and other fields during apply.
 		},
+		"addresses": schema.MapAttribute{
+			MarkdownDescription: "IP addresses of the nodes, the key is the node label, the value is a map of interface label to the list of IPv4 and IPv6 addresses of that interface. Only interfaces with addresses are included.",
+			Computed:            true,
+			ElementType:         types.MapType{ElemType: types.ListType{ElemType: types.StringType}},
+		},
+		"management_ip": schema.MapAttribute{
+			MarkdownDescription: "Management address of the nodes, the key is the node label, the value is the first reachable IPv4 address of the interfaces matching `management_interface` (in slot order), or the first reachable IPv6 address if none of them has an IPv4 address. Link-local addresses are skipped. Nodes without such an address are not included.",
+			Computed:            true,
+			ElementType:         types.StringType,
+		},
+		"management_interface": schema.StringAttribute{
+			MarkdownDescription: "Regular expression selecting the interfaces (by label) which are considered for `management_ip`, e.g. `^(GigabitEthernet0/0|eth0)$`. Defaults to all interfaces.",
+			Optional:            true,
+			Validators: []validator.String{
+				cmlvalidator.Regexp{},
+			},
+		},
+		"wait_for_addresses": lifecycleWaitForAddresses(),
+		"layout":             lifecycleLayout(),
 		"update_triggers": schema.MapAttribute{
 			Description: "Synthetic trigger map; lifecycle Update is planned when values change.",
 			Optional:    true,
@@ -95,22 +140,53 @@ func Lifecycle() map[string]schema.Attribute {
 				mapplanmodifier.UseStateForUnknown(),
 			},
 		},
-		"configs": schema.MapAttribute{
-			Description: "Map of node configurations to store into nodes, the key is the label of the node, the value is the node configuration.",
-			Optional:    true,
-			ElementType: types.StringType,
-			PlanModifiers: []planmodifier.Map{
-				mapplanmodifier.RequiresReplace(),
+		"restart_triggers": schema.MapAttribute{
+			MarkdownDescription: "Rolling restart trigger map, the key is a node label, a glob pattern on the node label or a tag prefixed with `tag:` (e.g. `tag:edge`). When the value of a key changes (or a key is added), the selected running nodes are stopped, optionally wiped (see `restart_wipe`) and started again. The nodes are restarted one batch at a time in `staging` order, the next batch is restarted after the previous one has converged. Only applies when the lab is and stays `STARTED`.",
+			Optional:            true,
+			ElementType:         types.StringType,
+		},
+		"restart_wipe": schema.BoolAttribute{
+			MarkdownDescription: "If set to `true` then nodes restarted by `restart_triggers` are wiped before they are started again. Defaults to `false`.",
+			Optional:            true,
+		},
+		"allow_wipe": schema.BoolAttribute{
+			MarkdownDescription: "Moving the lab to `DEFINED_ON_CORE` stops and wipes all nodes, discarding their runtime state. The plan lists the affected nodes in a warning. If set to `false`, such plans fail instead. Defaults to `true`.",
+			Optional:            true,
+		},
+		"auto_stop_after": schema.StringAttribute{
+			MarkdownDescription: "Stop the lab automatically after it has been running for the given duration, as in `8h`. The deadline is recorded in the lab notes when the lifecycle starts the lab. Any plan past the deadline plans a transition to `STOPPED` with a warning, as long as `state` is not configured. With a configured `state`, an overdue lab only produces a warning. Changing the value sets a new deadline, as does starting the lab again.",
+			Optional:            true,
+			Validators: []validator.String{
+				cmlvalidator.Duration{},
 			},
 		},
-		"named_configs": schema.MapAttribute{
-			Description: "Map of named node configurations to store into nodes, the key is the label of the node, the value is the node configuration.",
-			Optional:    true,
-			ElementType: types.ListType{ElemType: NamedConfigAttrType},
-			PlanModifiers: []planmodifier.Map{
-				mapplanmodifier.RequiresReplace(),
+		"auto_stop_deadline": schema.StringAttribute{
+			MarkdownDescription: "Auto stop deadline of the lab (RFC3339), see `auto_stop_after`. Read from the lab notes on refresh so that a deadline can be extended outside of Terraform.",
+			Computed:            true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
 			},
 		},
+		"configs": schema.MapAttribute{
+			MarkdownDescription: "Map of node configurations to store into nodes, the key is the label of the node, a glob pattern on the node label (e.g. `leaf-*`) or a tag prefixed with `tag:` (e.g. `tag:edge`), the value is the node configuration. A node label takes precedence over patterns which take precedence over tags. Configurations selected by a pattern or tag are Go templates rendered per node with `.Label`, `.Hostname`, `.NodeDefinition` and `.Tags`, as in `hostname {{ .Hostname }}`. With a topology, changes are applied in place to wiped nodes, other nodes are replaced.",
+			Optional:            true,
+			ElementType:         types.StringType,
+		},
+		"named_configs": schema.MapAttribute{
+			MarkdownDescription: "Map of named node configurations to store into nodes, the key is the label of the node, a glob pattern on the node label or a tag prefixed with `tag:`, the value is the list of named node configurations. Keys and templates work like `configs`. With a topology, changes are applied in place to wiped nodes, other nodes are replaced.",
+			Optional:            true,
+			ElementType:         types.ListType{ElemType: NamedConfigAttrType},
+		},
+		"config_templates": schema.MapAttribute{
+			MarkdownDescription: "Map of node configuration templates, keys work like `configs`. Templates are Go templates rendered per node with `.ID`, `.Label`, `.Hostname`, `.NodeDefinition`, `.Tags`, `.Interfaces` (interface labels in slot order), `.LabID`, `.LabTitle` and `.Vars` (see `config_vars`), as in `hostname {{ .Hostname }}`. Node IDs and interfaces are only known after the topology has been imported, when a change is planned they are taken from the topology. Can't be combined with `configs` or `named_configs`.",
+			Optional:            true,
+			ElementType:         types.StringType,
+		},
+		"config_vars": schema.MapAttribute{
+			MarkdownDescription: "Variables available as `.Vars` in `config_templates` and in `configs` / `named_configs` selected by pattern or tag, as in `{{ .Vars.domain }}`. Referencing an undefined variable is an error.",
+			Optional:            true,
+			ElementType:         types.StringType,
+		},
 		"timeouts": schema.SingleNestedAttribute{
 			MarkdownDescription: "Timeouts for operations, given as a parsable string as in `60m` or `2h`.",
 			Optional:            true,
@@ -130,8 +206,8 @@ func Lifecycle() map[string]schema.Attribute {
 					},
 				},
 				"delete": schema.StringAttribute{
-					Optional:    true,
-					Description: "Delete timeout (currently unused).",
+					Optional:            true,
+					MarkdownDescription: "Delete timeout, bounds the complete teardown (staged stop, wipe and delete). Defaults to `2h`.",
 					Validators: []validator.String{
 						cmlvalidator.Duration{},
 					},
@@ -154,6 +230,17 @@ func Lifecycle() map[string]schema.Attribute {
 					Optional:            true,
 					MarkdownDescription: "If set to `true` (which is the default) then all nodes which are not matched by the stages list and which are still unstarted after running all stages will be started.",
 				},
+				"abort_on_failure": schema.BoolAttribute{
+					Optional:            true,
+					MarkdownDescription: "If set to `true`, no further stages (and no remaining nodes) are started when starting the nodes of a stage fails. Defaults to `false`.",
+				},
+				"mode": schema.StringAttribute{
+					Optional:            true,
+					MarkdownDescription: "Where staging is executed. With `server`, the stage order is translated into node `priority` values (earlier stages get lower values) and the controller runs the lab node staging, this requires CML 2.10 or later. Server-side staging overwrites the `priority` of the staged nodes and enables the lab `node_staging`, don't combine it with `priority` of `cml2_node` or `node_staging` of `cml2_lab`. The previous values are recorded and restored when the mode changes to `client`, `staging` is removed or the lifecycle of a lab given by `lab_id` is destroyed. With `client` (the default), the provider starts the nodes of each stage and waits for them to converge. `auto` uses `server` when the controller supports it and `client` otherwise.",
+					Validators: []validator.String{
+						stringvalidator.OneOf(StagingModeAuto, StagingModeServer, StagingModeClient),
+					},
+				},
 			},
 		},
 		"elements": schema.ListAttribute{
@@ -165,5 +252,12 @@ func Lifecycle() map[string]schema.Attribute {
 			},
 			DeprecationMessage: "use depends_on instead",
 		},
+		"on_failure": schema.StringAttribute{
+			MarkdownDescription: "Policy when creating the lifecycle fails after the lab has been imported, e.g. due to a failed configuration injection or node start. `keep` (the default) records the lab in state as tainted so that the next apply replaces it, `stop` stops the lab and records it as tainted, `destroy` stops, wipes and deletes the lab. For labs referenced via `lab_id`, `destroy` behaves like `stop` as the lab is not owned by the lifecycle.",
+			Optional:            true,
+			Validators: []validator.String{
+				stringvalidator.OneOf(OnFailureKeep, OnFailureStop, OnFailureDestroy),
+			},
+		},
 	}
 }
@@ -124,7 +124,7 @@ func Link() map[string]schema.Attribute {
 			},
 		},
 		"slot_a": schema.Int64Attribute{
-			Description: "Optional interface slot on node A (src), if not provided use next free.",
+			Description: "Optional interface slot on node A (src), if not provided use next free. Changing the slot moves the link in place, the controller re-creates it with a new ID, label and capture key.",
 			Optional:    true,
 			Computed:    true,
 			PlanModifiers: []planmodifier.Int64{
@@ -132,7 +132,7 @@ func Link() map[string]schema.Attribute {
 			},
 		},
 		"slot_b": schema.Int64Attribute{
-			Description: "Optional interface slot on node B (dst), if not provided use next free.",
+			Description: "Optional interface slot on node B (dst), if not provided use next free. Changing the slot moves the link in place, the controller re-creates it with a new ID, label and capture key.",
 			Optional:    true,
 			Computed:    true,
 			PlanModifiers: []planmodifier.Int64{
@@ -3,6 +3,7 @@ package cmlschema
 import (
 	"context"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -12,6 +13,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
@@ -45,6 +47,25 @@ type NodeModel struct {
 	Generation      types.String `tfsdk:"generation"`
 }
 
+// NodeResourceModel is the Terraform representation of the cml2_node
+// resource, the node and the settings which only exist in the resource.
+type NodeResourceModel struct {
+	NodeModel
+
+	WaitForAddresses types.Object `tfsdk:"wait_for_addresses"`
+	ConfigTemplate   types.String `tfsdk:"config_template"`
+	ConfigVars       types.Map    `tfsdk:"config_vars"`
+	RestartOnChange  types.String `tfsdk:"restart_on_change"`
+}
+
+// Restart policies of a node, applied when attributes which can only be
+// changed while the node is DEFINED_ON_CORE are changed on a started node.
+const (
+	RestartOnChangeNever           = "never"
+	RestartOnChangeStopWipeRestart = "stop_wipe_restart"
+	RestartOnChangeReplace         = "replace"
+)
+
 type serialDeviceModel struct {
 	ConsoleKey   types.String `tfsdk:"console_key"`
 	DeviceNumber types.Int64  `tfsdk:"device_number"`
@@ -369,6 +390,30 @@ func Node() map[string]schema.Attribute {
 	}
 }
 
+// NodeResource returns the schema attributes of the cml2_node resource, the
+// node attributes and the resource only settings.
+func NodeResource() map[string]schema.Attribute {
+	attrs := Node()
+	attrs["wait_for_addresses"] = nodeWaitForAddresses()
+	attrs["config_template"] = schema.StringAttribute{
+		MarkdownDescription: "Node configuration template, a Go template which is rendered into `configuration` with `.ID`, `.Label`, `.Hostname`, `.NodeDefinition`, `.Tags`, `.Interfaces` (interface labels in slot order), `.LabID`, `.LabTitle` and `.Vars` (see `config_vars`), as in `hostname {{ .Hostname }}`. The rendered result is compared to the node configuration. Node ID and interfaces are known once the node exists, templates using them render at apply time when the node is created. Can't be combined with `configuration` or `configurations`.",
+		Optional:            true,
+	}
+	attrs["config_vars"] = schema.MapAttribute{
+		MarkdownDescription: "Variables available as `.Vars` in `config_template`, as in `{{ .Vars.domain }}`. Referencing an undefined variable is an error.",
+		Optional:            true,
+		ElementType:         types.StringType,
+	}
+	attrs["restart_on_change"] = schema.StringAttribute{
+		MarkdownDescription: "How changes of `configuration`, `configurations`, `imagedefinition`, `ram`, `cpus`, `cpu_limit`, `boot_disk_size` and `data_volume` are applied once the node has been started: `replace` replaces the node, `stop_wipe_restart` stops and wipes the node, applies the changes and starts the node again if it was running (a stopped node is left in `DEFINED_ON_CORE`), `never` fails the plan. Wiping discards the disks of the node. Defaults to `replace`.",
+		Optional:            true,
+		Validators: []validator.String{
+			stringvalidator.OneOf(RestartOnChangeNever, RestartOnChangeStopWipeRestart, RestartOnChangeReplace),
+		},
+	}
+	return attrs
+}
+
 func newNamedConfig(ctx context.Context, nc models.NodeConfig, diags *diag.Diagnostics) attr.Value {
 	namedConfig := NamedConfigModel{
 		Name:    types.StringValue(nc.Name),
@@ -0,0 +1,118 @@
+package cmlschema
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlvalidator"
+)
+
+// NodeStateModel is the Terraform representation of the desired state of a
+// single node.
+type NodeStateModel struct {
+	ID               types.String `tfsdk:"id"`
+	LabID            types.String `tfsdk:"lab_id"`
+	NodeID           types.String `tfsdk:"node_id"`
+	State            types.String `tfsdk:"state"`
+	Wait             types.Bool   `tfsdk:"wait"`
+	OnDestroy        types.String `tfsdk:"on_destroy"`
+	Booted           types.Bool   `tfsdk:"booted"`
+	Timeouts         types.Object `tfsdk:"timeouts"`
+	WaitForAddresses types.Object `tfsdk:"wait_for_addresses"`
+}
+
+// Node state destroy policies, see the on_destroy attribute.
+const (
+	OnDestroyNone = "none"
+	OnDestroyStop = "stop"
+	OnDestroyWipe = "wipe"
+)
+
+// NodeStateTimeoutsModel holds the timeouts of the node state resource.
+type NodeStateTimeoutsModel struct {
+	Create types.String `tfsdk:"create"`
+	Update types.String `tfsdk:"update"`
+	Delete types.String `tfsdk:"delete"`
+}
+
+// NodeStateTimeoutsAttrType is the attribute type map for
+// NodeStateTimeoutsModel.
+var NodeStateTimeoutsAttrType = map[string]attr.Type{
+	"create": types.StringType,
+	"update": types.StringType,
+	"delete": types.StringType,
+}
+
+func nodeStateTimeout(name string) schema.StringAttribute {
+	return schema.StringAttribute{
+		MarkdownDescription: name + " timeout. Defaults to `30m`.",
+		Optional:            true,
+		Validators: []validator.String{
+			cmlvalidator.Duration{},
+		},
+	}
+}
+
+// NodeState returns the schema for the node state resource.
+func NodeState() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"id": schema.StringAttribute{
+			Description: "Resource identifier, identical to the node ID.",
+			Computed:    true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
+			},
+		},
+		"lab_id": schema.StringAttribute{
+			Description: "Lab ID that contains the node (UUID).",
+			Required:    true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.RequiresReplace(),
+			},
+		},
+		"node_id": schema.StringAttribute{
+			Description: "Node ID to control the state of (UUID).",
+			Required:    true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.RequiresReplace(),
+			},
+		},
+		"state": schema.StringAttribute{
+			MarkdownDescription: "Desired node state, one of `DEFINED_ON_CORE`, `STARTED` or `STOPPED`. `DEFINED_ON_CORE` stops and wipes the node, `STOPPED` keeps the disks of a node which has been started (a node which has never been started stays `DEFINED_ON_CORE`).",
+			Required:            true,
+			Validators: []validator.String{
+				cmlvalidator.LabState{},
+			},
+		},
+		"wait": schema.BoolAttribute{
+			MarkdownDescription: "If set to `true` then wait until a started node has `BOOTED` and a stopped or wiped node has reached its state. Defaults to `true`.",
+			Optional:            true,
+		},
+		"on_destroy": schema.StringAttribute{
+			MarkdownDescription: "What happens to the node when the resource is destroyed: `none` leaves the node as it is, `stop` stops the node, `wipe` stops and wipes the node which discards its disks. Defaults to `stop`.",
+			Optional:            true,
+			Validators: []validator.String{
+				stringvalidator.OneOf(OnDestroyNone, OnDestroyStop, OnDestroyWipe),
+			},
+		},
+		"booted": schema.BoolAttribute{
+			MarkdownDescription: "Set to `true` when the node has booted.",
+			Computed:            true,
+		},
+		"timeouts": schema.SingleNestedAttribute{
+			MarkdownDescription: "Timeouts for operations, given as a parsable string as in `60m` or `2h`.",
+			Optional:            true,
+			Attributes: map[string]schema.Attribute{
+				"create": nodeStateTimeout("Create"),
+				"update": nodeStateTimeout("Update"),
+				"delete": nodeStateTimeout("Delete"),
+			},
+		},
+		"wait_for_addresses": nodeWaitForAddresses(),
+	}
+}
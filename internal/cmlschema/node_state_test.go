@@ -0,0 +1,30 @@
+package cmlschema_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+)
+
+func TestNodeState(t *testing.T) {
+	ctx := context.Background()
+
+	stateschema := schema.Schema{
+		Attributes: cmlschema.NodeState(),
+	}
+	assert.Len(t, stateschema.Attributes, 9)
+
+	timeouts, ok := stateschema.Attributes["timeouts"].(schema.SingleNestedAttribute)
+	assert.True(t, ok)
+	assert.Len(t, timeouts.Attributes, len(cmlschema.NodeStateTimeoutsAttrType))
+
+	attrType, diags := stateschema.TypeAtPath(ctx, path.Root("wait_for_addresses"))
+	assert.False(t, diags.HasError())
+	assert.Equal(t, types.ObjectType{AttrTypes: cmlschema.NodeWaitForAddressesAttrType}, attrType)
+}
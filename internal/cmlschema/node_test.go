@@ -112,6 +112,19 @@ func TestNodeAttrs(t *testing.T) {
 	assert.Equal(t, types.StringType, got)
 }
 
+func TestNodeResourceAttrs(t *testing.T) {
+	nodeschema := schema.Schema{
+		Attributes: cmlschema.NodeResource(),
+	}
+
+	got, diag := nodeschema.TypeAtPath(context.TODO(), path.Root("wait_for_addresses").AtName("timeout"))
+	t.Log(diag.Errors())
+	assert.Equal(t, 27, len(nodeschema.Attributes))
+	assert.False(t, diag.HasError())
+	assert.Equal(t, types.StringType, got)
+	assert.Equal(t, 23, len(cmlschema.Node()))
+}
+
 func TestNewNamedConfigs(t *testing.T) {
 	diag := &diag.Diagnostics{}
 	ctx := context.Background()
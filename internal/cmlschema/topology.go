@@ -0,0 +1,168 @@
+package cmlschema
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// TopologyModel is the Terraform representation of a set of nodes and links
+// of a lab managed as a whole.
+type TopologyModel struct {
+	ID      types.String `tfsdk:"id"`
+	LabID   types.String `tfsdk:"lab_id"`
+	Nodes   types.Map    `tfsdk:"nodes"`
+	Links   types.Set    `tfsdk:"links"`
+	NodeIDs types.Map    `tfsdk:"node_ids"`
+}
+
+// TopologyNodeModel is a node of the topology resource, the node label is
+// the map key.
+type TopologyNodeModel struct {
+	NodeDefinition  types.String `tfsdk:"nodedefinition"`
+	ImageDefinition types.String `tfsdk:"imagedefinition"`
+	Configuration   types.String `tfsdk:"configuration"`
+	Tags            types.List   `tfsdk:"tags"`
+	X               types.Int64  `tfsdk:"x"`
+	Y               types.Int64  `tfsdk:"y"`
+	RAM             types.Int64  `tfsdk:"ram"`
+	CPUs            types.Int64  `tfsdk:"cpus"`
+}
+
+// TopologyNodeAttrType is the attribute type map for TopologyNodeModel.
+var TopologyNodeAttrType = map[string]attr.Type{
+	"nodedefinition":  types.StringType,
+	"imagedefinition": types.StringType,
+	"configuration":   types.StringType,
+	"tags":            types.ListType{ElemType: types.StringType},
+	"x":               types.Int64Type,
+	"y":               types.Int64Type,
+	"ram":             types.Int64Type,
+	"cpus":            types.Int64Type,
+}
+
+// TopologyLinkModel is a link of the topology resource, endpoints are given
+// by node label and slot.
+type TopologyLinkModel struct {
+	NodeA types.String `tfsdk:"node_a"`
+	SlotA types.Int64  `tfsdk:"slot_a"`
+	NodeB types.String `tfsdk:"node_b"`
+	SlotB types.Int64  `tfsdk:"slot_b"`
+}
+
+// TopologyLinkAttrType is the attribute type map for TopologyLinkModel.
+var TopologyLinkAttrType = map[string]attr.Type{
+	"node_a": types.StringType,
+	"slot_a": types.Int64Type,
+	"node_b": types.StringType,
+	"slot_b": types.Int64Type,
+}
+
+func topologyEndpoint(side, other string) map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"node_" + side: schema.StringAttribute{
+			MarkdownDescription: "Label of the node (" + other + ") attached to the link, must be a key of `nodes`.",
+			Required:            true,
+		},
+		"slot_" + side: schema.Int64Attribute{
+			MarkdownDescription: "Interface slot on the node (" + other + ").",
+			Required:            true,
+			Validators: []validator.Int64{
+				int64validator.AtLeast(0),
+			},
+		},
+	}
+}
+
+func topologyLink() map[string]schema.Attribute {
+	attrs := topologyEndpoint("a", "A")
+	for name, attr := range topologyEndpoint("b", "B") {
+		attrs[name] = attr
+	}
+	return attrs
+}
+
+// Topology returns the schema for the topology resource.
+func Topology() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"id": schema.StringAttribute{
+			Description: "Resource identifier, identical to the lab ID.",
+			Computed:    true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
+			},
+		},
+		"lab_id": schema.StringAttribute{
+			Description: "Lab ID the nodes and links are created in (UUID).",
+			Required:    true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.RequiresReplace(),
+			},
+		},
+		"nodes": schema.MapNestedAttribute{
+			MarkdownDescription: "Nodes of the topology, the key is the node label. Nodes are identified by their label: changing the node or image definition replaces the node, as does changing the configuration or hardware of a node which isn't wiped.",
+			Required:            true,
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: map[string]schema.Attribute{
+					"nodedefinition": schema.StringAttribute{
+						MarkdownDescription: "Node definition ID, e.g. `iosv`.",
+						Required:            true,
+					},
+					"imagedefinition": schema.StringAttribute{
+						Description: "Image definition ID, the default image of the node definition is used if not set.",
+						Optional:    true,
+					},
+					"configuration": schema.StringAttribute{
+						Description: "Day 0 configuration of the node.",
+						Optional:    true,
+					},
+					"tags": schema.ListAttribute{
+						Description: "Tags of the node.",
+						Optional:    true,
+						Computed:    true,
+						ElementType: types.StringType,
+						Default:     listdefault.StaticValue(types.ListValueMust(types.StringType, []attr.Value{})),
+					},
+					"x": schema.Int64Attribute{
+						Description: "X coordinate of the node.",
+						Optional:    true,
+						Computed:    true,
+						Default:     int64default.StaticInt64(0),
+					},
+					"y": schema.Int64Attribute{
+						Description: "Y coordinate of the node.",
+						Optional:    true,
+						Computed:    true,
+						Default:     int64default.StaticInt64(0),
+					},
+					"ram": schema.Int64Attribute{
+						Description: "Memory of the node in MB, the node definition default is used if not set.",
+						Optional:    true,
+					},
+					"cpus": schema.Int64Attribute{
+						Description: "Number of CPUs of the node, the node definition default is used if not set.",
+						Optional:    true,
+					},
+				},
+			},
+		},
+		"links": schema.SetNestedAttribute{
+			MarkdownDescription: "Links between the nodes, identified by their endpoints.",
+			Optional:            true,
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: topologyLink(),
+			},
+		},
+		"node_ids": schema.MapAttribute{
+			Description: "Node IDs (UUID), the key is the node label.",
+			Computed:    true,
+			ElementType: types.StringType,
+		},
+	}
+}
@@ -0,0 +1,34 @@
+package cmlschema_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+)
+
+func TestTopology(t *testing.T) {
+	ctx := context.Background()
+
+	topologyschema := schema.Schema{
+		Attributes: cmlschema.Topology(),
+	}
+	assert.Len(t, topologyschema.Attributes, 5)
+
+	nodes, ok := topologyschema.Attributes["nodes"].(schema.MapNestedAttribute)
+	assert.True(t, ok)
+	assert.Len(t, nodes.NestedObject.Attributes, len(cmlschema.TopologyNodeAttrType))
+
+	links, ok := topologyschema.Attributes["links"].(schema.SetNestedAttribute)
+	assert.True(t, ok)
+	assert.Len(t, links.NestedObject.Attributes, len(cmlschema.TopologyLinkAttrType))
+
+	attrType, diags := topologyschema.TypeAtPath(ctx, path.Root("node_ids"))
+	assert.False(t, diags.HasError())
+	assert.Equal(t, types.MapType{ElemType: types.StringType}, attrType)
+}
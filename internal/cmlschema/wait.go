@@ -0,0 +1,88 @@
+package cmlschema
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlvalidator"
+)
+
+// Address families for the wait_for_addresses readiness checks.
+const (
+	AddressFamilyAny  = "any"
+	AddressFamilyIPv4 = "ipv4"
+	AddressFamilyIPv6 = "ipv6"
+)
+
+// NodeWaitForAddressesModel is the Terraform representation of the address
+// readiness check of a node.
+type NodeWaitForAddressesModel struct {
+	Interfaces    types.List   `tfsdk:"interfaces"`
+	AddressFamily types.String `tfsdk:"address_family"`
+	Timeout       types.String `tfsdk:"timeout"`
+}
+
+// NodeWaitForAddressesAttrType is the attribute type map for
+// NodeWaitForAddressesModel.
+var NodeWaitForAddressesAttrType = map[string]attr.Type{
+	"interfaces":     types.ListType{ElemType: types.StringType},
+	"address_family": types.StringType,
+	"timeout":        types.StringType,
+}
+
+func addressFamily() schema.StringAttribute {
+	return schema.StringAttribute{
+		MarkdownDescription: "Address family to wait for, one of `any`, `ipv4` or `ipv6`. Defaults to `any`. Link-local addresses are not considered.",
+		Optional:            true,
+		Validators: []validator.String{
+			stringvalidator.OneOf(AddressFamilyAny, AddressFamilyIPv4, AddressFamilyIPv6),
+		},
+	}
+}
+
+func nodeWaitForAddresses() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Wait until interfaces of the node report IP addresses. The check runs at the end of create and update if the node is running.",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"interfaces": schema.ListAttribute{
+				MarkdownDescription: "Labels of the interfaces to wait for. If not set, all interfaces connected to external connectors (directly or via unmanaged switches) are selected.",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"address_family": addressFamily(),
+			"timeout": schema.StringAttribute{
+				MarkdownDescription: "Maximum time to wait, as in `5m`. Defaults to `10m`.",
+				Optional:            true,
+				Validators: []validator.String{
+					cmlvalidator.Duration{},
+				},
+			},
+		},
+	}
+}
+
+func lifecycleWaitForAddresses() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: "Wait until interfaces of the lab report IP addresses after the lab has been started. `booted` can be `true` before DHCP has completed.",
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"interfaces": schema.MapAttribute{
+				MarkdownDescription: "Interfaces to wait for, the key is the node label, the value is the list of interface labels. If not set, all interfaces connected to external connectors (directly or via unmanaged switches) are selected.",
+				Optional:            true,
+				ElementType:         types.ListType{ElemType: types.StringType},
+			},
+			"address_family": addressFamily(),
+			"timeout": schema.StringAttribute{
+				MarkdownDescription: "Maximum time to wait, as in `5m`. Defaults to the create or update timeout.",
+				Optional:            true,
+				Validators: []validator.String{
+					cmlvalidator.Duration{},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,50 @@
+package cmlvalidator
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ validator.String = Regexp{}
+
+// Regexp validates that a string is a valid (Go RE2) regular expression.
+type Regexp struct{}
+
+// Description returns a plain text description of the validator.
+func (v Regexp) Description(ctx context.Context) string {
+	return "a valid regular expression"
+}
+
+// MarkdownDescription returns a markdown formatted description of the
+// validator's behavior, suitable for a practitioner to understand its impact.
+func (v Regexp) MarkdownDescription(ctx context.Context) string {
+	return "a valid regular expression (Go RE2 syntax)"
+}
+
+// ValidateString runs the main validation logic of the validator, reading
+// configuration data out of `req` and updating `resp` with diagnostics.
+func (v Regexp) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	var expr types.String
+	resp.Diagnostics.Append(tfsdk.ValueAs(ctx, req.ConfigValue, &expr)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if expr.IsUnknown() || expr.IsNull() {
+		return
+	}
+
+	_, err := regexp.Compile(expr.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid regular expression",
+			err.Error(),
+		)
+		return
+	}
+}
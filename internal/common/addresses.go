@@ -0,0 +1,281 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/rschmied/gocmlclient/pkg/client"
+	"github.com/rschmied/gocmlclient/pkg/models"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+)
+
+// InterfaceRef identifies an interface by node label and interface label.
+type InterfaceRef struct {
+	Node      string
+	Interface string
+}
+
+func (i InterfaceRef) String() string {
+	return i.Node + "/" + i.Interface
+}
+
+// InterfaceSelector returns the interfaces of the lab to wait for.
+type InterfaceSelector func(lab *models.Lab) ([]InterfaceRef, error)
+
+// ReachableAddress returns the address without prefix length if it is usable
+// to reach the node. Link-local, loopback and unparsable addresses are
+// skipped.
+func ReachableAddress(address string) (string, bool) {
+	host, _, _ := strings.Cut(address, "/")
+	ip, err := netip.ParseAddr(host)
+	if err != nil || ip.IsLinkLocalUnicast() || ip.IsLoopback() || ip.IsUnspecified() {
+		return "", false
+	}
+	return ip.String(), true
+}
+
+// HasAddress reports whether the running interface has a reachable address
+// of the given family.
+func HasAddress(iface *models.Interface, family string) bool {
+	if iface == nil || !iface.Runs() {
+		return false
+	}
+	check := func(addresses []string) bool {
+		for _, address := range addresses {
+			if _, ok := ReachableAddress(address); ok {
+				return true
+			}
+		}
+		return false
+	}
+	switch family {
+	case cmlschema.AddressFamilyIPv4:
+		return check(iface.IP4)
+	case cmlschema.AddressFamilyIPv6:
+		return check(iface.IP6)
+	}
+	return check(iface.IP4) || check(iface.IP6)
+}
+
+// ExternalInterfaces returns the interfaces which are connected to an
+// external connector, either directly or through unmanaged switches. The
+// interfaces of the external connectors and switches themselves are not
+// included.
+func ExternalInterfaces(lab *models.Lab) []InterfaceRef {
+	type end struct {
+		node  *models.Node
+		iface models.UUID
+	}
+	peers := map[models.UUID][]end{}
+	for _, link := range lab.Links {
+		if link == nil {
+			continue
+		}
+		src, dst := lab.Nodes[link.SrcNode], lab.Nodes[link.DstNode]
+		if src == nil || dst == nil {
+			continue
+		}
+		peers[src.ID] = append(peers[src.ID], end{dst, link.DstID})
+		peers[dst.ID] = append(peers[dst.ID], end{src, link.SrcID})
+	}
+
+	seen := map[models.UUID]bool{}
+	queue := []*models.Node{}
+	for _, node := range lab.Nodes {
+		if node != nil && node.NodeDefinition == "external_connector" {
+			seen[node.ID] = true
+			queue = append(queue, node)
+		}
+	}
+
+	found := map[InterfaceRef]bool{}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, peer := range peers[node.ID] {
+			switch peer.node.NodeDefinition {
+			case "unmanaged_switch":
+				if !seen[peer.node.ID] {
+					seen[peer.node.ID] = true
+					queue = append(queue, peer.node)
+				}
+			case "external_connector":
+			default:
+				for _, iface := range peer.node.Interfaces {
+					if iface != nil && iface.ID == peer.iface {
+						found[InterfaceRef{peer.node.Label, iface.Label}] = true
+					}
+				}
+			}
+		}
+	}
+
+	result := make([]InterfaceRef, 0, len(found))
+	for ref := range found {
+		result = append(result, ref)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].String() < result[j].String()
+	})
+	return result
+}
+
+// MissingAddresses returns the selected interfaces which have no reachable
+// address of the given family yet. Interfaces which don't exist in the lab
+// are reported as an error.
+func MissingAddresses(lab *models.Lab, selected []InterfaceRef, family string) ([]InterfaceRef, error) {
+	ifaces := map[InterfaceRef]*models.Interface{}
+	for _, node := range lab.Nodes {
+		if node == nil {
+			continue
+		}
+		for _, iface := range node.Interfaces {
+			if iface != nil {
+				ifaces[InterfaceRef{node.Label, iface.Label}] = iface
+			}
+		}
+	}
+
+	missing := []InterfaceRef{}
+	for _, ref := range selected {
+		iface, ok := ifaces[ref]
+		if !ok {
+			return nil, fmt.Errorf("interface %s not found in lab", ref)
+		}
+		if !HasAddress(iface, family) {
+			missing = append(missing, ref)
+		}
+	}
+	return missing, nil
+}
+
+func refList(refs []InterfaceRef) string {
+	list := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		list = append(list, ref.String())
+	}
+	return strings.Join(list, ", ")
+}
+
+// WaitForAddresses waits until all interfaces returned by the selector report
+// an address of the given family or the timeout is reached.
+func WaitForAddresses(ctx context.Context, client *client.Client, diags *diag.Diagnostics, id string, selector InterfaceSelector, family, timeout string) {
+	snoozeFor := 5 // seconds
+
+	tflog.Info(ctx, "waiting for addresses", map[string]any{"family": family})
+
+	tov, err := time.ParseDuration(timeout)
+	if err != nil {
+		diags.AddError(ErrorLabel, fmt.Sprintf("can't parse timeout %q: %s", timeout, err))
+		return
+	}
+	endTime := time.Now().Add(tov)
+
+	ticker := time.NewTicker(time.Second * time.Duration(snoozeFor))
+	defer ticker.Stop()
+
+	attempts := 0
+
+	for {
+		lab, err := client.Lab.GetByID(ctx, models.UUID(id), true)
+		if err != nil {
+			diags.AddError(
+				ErrorLabel,
+				fmt.Sprintf("Wait for addresses, got error: %s", err),
+			)
+			return
+		}
+		selected, err := selector(&lab)
+		if err != nil {
+			diags.AddError(ErrorLabel, fmt.Sprintf("Wait for addresses, got error: %s", err))
+			return
+		}
+		missing, err := MissingAddresses(&lab, selected, family)
+		if err != nil {
+			diags.AddError(ErrorLabel, fmt.Sprintf("Wait for addresses, got error: %s", err))
+			return
+		}
+		if len(missing) == 0 {
+			tflog.Info(ctx, "addresses assigned", map[string]any{"interfaces": len(selected), "seconds": attempts * snoozeFor})
+			return
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		if time.Now().After(endTime) {
+			tflog.Warn(ctx, "address timeout", map[string]any{"timeout": timeout, "seconds": attempts * snoozeFor})
+			diags.AddError(
+				ErrorLabel,
+				fmt.Sprintf("ran into timeout (max %s) waiting for addresses on %s", timeout, refList(missing)),
+			)
+			return
+		}
+		attempts++
+		tflog.Info(
+			ctx, "waiting for addresses",
+			map[string]any{"seconds": attempts * snoozeFor, "missing": refList(missing)},
+		)
+	}
+}
+
+// DefaultNodeAddressTimeout is the timeout of the address readiness check of
+// a node if none is configured.
+const DefaultNodeAddressTimeout = "10m"
+
+// WaitForNodeAddresses runs the wait_for_addresses readiness check of a single
+// node, the value is a cmlschema.NodeWaitForAddressesModel object. Without
+// configured interfaces, the interfaces of the node connected to external
+// connectors are selected.
+func WaitForNodeAddresses(ctx context.Context, client *client.Client, diags *diag.Diagnostics, labID, label string, value types.Object) {
+	var wait cmlschema.NodeWaitForAddressesModel
+	diags.Append(tfsdk.ValueAs(ctx, value, &wait)...)
+	if diags.HasError() {
+		return
+	}
+
+	var labels []string
+	if !wait.Interfaces.IsNull() {
+		diags.Append(wait.Interfaces.ElementsAs(ctx, &labels, false)...)
+		if diags.HasError() {
+			return
+		}
+	}
+	selector := func(lab *models.Lab) ([]InterfaceRef, error) {
+		refs := []InterfaceRef{}
+		if labels != nil {
+			for _, iface := range labels {
+				refs = append(refs, InterfaceRef{Node: label, Interface: iface})
+			}
+			return refs, nil
+		}
+		for _, ref := range ExternalInterfaces(lab) {
+			if ref.Node == label {
+				refs = append(refs, ref)
+			}
+		}
+		return refs, nil
+	}
+
+	family := cmlschema.AddressFamilyAny
+	if !wait.AddressFamily.IsNull() {
+		family = wait.AddressFamily.ValueString()
+	}
+	timeout := DefaultNodeAddressTimeout
+	if !wait.Timeout.IsNull() && wait.Timeout.ValueString() != "" {
+		timeout = wait.Timeout.ValueString()
+	}
+	WaitForAddresses(ctx, client, diags, labID, selector, family, timeout)
+}
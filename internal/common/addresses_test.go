@@ -0,0 +1,93 @@
+package common_test
+
+import (
+	"testing"
+
+	"github.com/rschmied/gocmlclient/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+)
+
+func TestReachableAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+		ok      bool
+	}{
+		{"192.168.1.1", "192.168.1.1", true},
+		{"192.168.1.1/24", "192.168.1.1", true},
+		{"2001:db8::1/64", "2001:db8::1", true},
+		{"169.254.10.1", "", false},
+		{"fe80::1", "", false},
+		{"127.0.0.1", "", false},
+		{"bla", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := common.ReachableAddress(tt.address)
+		assert.Equal(t, tt.want, got, tt.address)
+		assert.Equal(t, tt.ok, ok, tt.address)
+	}
+}
+
+func addressTestLab() *models.Lab {
+	iface := func(id, label string, ip4, ip6 []string) *models.Interface {
+		return &models.Interface{ID: models.UUID(id), Label: label, State: models.IfaceStateStarted, IP4: ip4, IP6: ip6}
+	}
+	return &models.Lab{
+		Nodes: models.NodeMap{
+			"ext": {ID: "ext", Label: "Internet", NodeDefinition: "external_connector", Interfaces: models.InterfaceList{iface("ext0", "port", nil, nil)}},
+			"ums": {ID: "ums", Label: "UMS", NodeDefinition: "unmanaged_switch", Interfaces: models.InterfaceList{
+				iface("ums0", "port0", nil, nil),
+				iface("ums1", "port1", nil, nil),
+			}},
+			"r1": {ID: "r1", Label: "r1", NodeDefinition: "iosv", Interfaces: models.InterfaceList{
+				iface("r1-0", "Gi0/0", []string{"192.168.1.1"}, nil),
+				iface("r1-1", "Gi0/1", nil, nil),
+			}},
+			"r2": {ID: "r2", Label: "r2", NodeDefinition: "iosv", Interfaces: models.InterfaceList{
+				iface("r2-0", "Gi0/0", nil, []string{"fe80::2"}),
+				iface("r2-1", "Gi0/1", nil, nil),
+			}},
+			"r3": {ID: "r3", Label: "r3", NodeDefinition: "iosv", Interfaces: models.InterfaceList{
+				iface("r3-0", "eth0", nil, []string{"2001:db8::3"}),
+			}},
+		},
+		Links: models.LinkList{
+			{SrcNode: "ext", SrcID: "ext0", DstNode: "ums", DstID: "ums0"},
+			{SrcNode: "r1", SrcID: "r1-0", DstNode: "ums", DstID: "ums1"},
+			{SrcNode: "r1", SrcID: "r1-1", DstNode: "r2", DstID: "r2-1"},
+			{SrcNode: "r3", SrcID: "r3-0", DstNode: "ext", DstID: "ext0"},
+		},
+	}
+}
+
+func TestExternalInterfaces(t *testing.T) {
+	assert.Equal(t,
+		[]common.InterfaceRef{{Node: "r1", Interface: "Gi0/0"}, {Node: "r3", Interface: "eth0"}},
+		common.ExternalInterfaces(addressTestLab()),
+	)
+}
+
+func TestMissingAddresses(t *testing.T) {
+	lab := addressTestLab()
+	selected := []common.InterfaceRef{
+		{Node: "r1", Interface: "Gi0/0"},
+		{Node: "r2", Interface: "Gi0/0"},
+		{Node: "r3", Interface: "eth0"},
+	}
+
+	missing, err := common.MissingAddresses(lab, selected, cmlschema.AddressFamilyAny)
+	require.NoError(t, err)
+	// link-local only
+	assert.Equal(t, []common.InterfaceRef{{Node: "r2", Interface: "Gi0/0"}}, missing)
+
+	missing, err = common.MissingAddresses(lab, selected, cmlschema.AddressFamilyIPv4)
+	require.NoError(t, err)
+	assert.Equal(t, []common.InterfaceRef{{Node: "r2", Interface: "Gi0/0"}, {Node: "r3", Interface: "eth0"}}, missing)
+
+	_, err = common.MissingAddresses(lab, []common.InterfaceRef{{Node: "r9", Interface: "eth0"}}, cmlschema.AddressFamilyAny)
+	assert.EqualError(t, err, "interface r9/eth0 not found in lab")
+}
@@ -30,6 +30,11 @@ type ProviderConfig struct {
 	// It is loaded lazily on first use.
 	nodeDefs       models.NodeDefinitionMap
 	nodeDefsLoaded bool
+
+	// imageDefs caches image definitions for plan-time validation.
+	// It is loaded lazily on first use.
+	imageDefs       []models.ImageDefinition
+	imageDefsLoaded bool
 }
 
 // Client returns the configured CML client.
@@ -85,6 +90,28 @@ func (r *ProviderConfig) NodeDefinitions(ctx context.Context) (models.NodeDefini
 	return r.nodeDefs, nil
 }
 
+// ImageDefinitions returns the controller's image definitions.
+// The result is cached for the lifetime of the provider instance.
+func (r *ProviderConfig) ImageDefinitions(ctx context.Context) ([]models.ImageDefinition, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.imageDefsLoaded {
+		return r.imageDefs, nil
+	}
+	if r.client == nil {
+		return nil, fmt.Errorf("client not initialized")
+	}
+
+	defs, err := r.client.ImageDefinition.ImageDefinitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.imageDefs = defs
+	r.imageDefsLoaded = true
+	return r.imageDefs, nil
+}
+
 // Initialize lazily creates the API client.
 func (r *ProviderConfig) Initialize(ctx context.Context, diags *diag.Diagnostics) *ProviderConfig {
 	r.mu.Lock()
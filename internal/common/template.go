@@ -0,0 +1,106 @@
+package common
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/rschmied/gocmlclient/pkg/models"
+)
+
+// ConfigTemplateData holds the node values which are available when a
+// configuration template is rendered, e.g. `hostname {{ .Hostname }}`.
+// Interfaces holds the interface labels in slot order.
+type ConfigTemplateData struct {
+	ID             string
+	Label          string
+	Hostname       string
+	NodeDefinition string
+	Tags           []string
+	Interfaces     []string
+	LabID          string
+	LabTitle       string
+	Vars           map[string]string
+}
+
+var hostnameInvalid = regexp.MustCompile(`[^A-Za-z0-9-]+`)
+
+// Hostname derives a hostname from a node label, characters which are not
+// valid in a hostname are replaced by dashes.
+func Hostname(label string) string {
+	return strings.Trim(hostnameInvalid.ReplaceAllString(label, "-"), "-")
+}
+
+// NewConfigTemplateData returns the template data of a node.
+func NewConfigTemplateData(label, nodeDefinition string, tags []string) ConfigTemplateData {
+	if tags == nil {
+		tags = []string{}
+	}
+	return ConfigTemplateData{
+		Label:          label,
+		Hostname:       Hostname(label),
+		NodeDefinition: nodeDefinition,
+		Tags:           tags,
+		Interfaces:     []string{},
+		Vars:           map[string]string{},
+	}
+}
+
+// NodeTemplateData returns the template data of a node of the given lab,
+// the lab can be nil.
+func NodeTemplateData(node *models.Node, lab *models.Lab) ConfigTemplateData {
+	data := NewConfigTemplateData(node.Label, node.NodeDefinition, node.Tags)
+	data.ID = string(node.ID)
+	data.LabID = string(node.LabID)
+	data.Interfaces = InterfaceLabels(node)
+	if lab != nil {
+		data.LabID = string(lab.ID)
+		data.LabTitle = lab.Title
+	}
+	return data
+}
+
+// InterfaceLabels returns the interface labels of the node ordered by slot,
+// interfaces without a slot (loopbacks) go last.
+func InterfaceLabels(node *models.Node) []string {
+	ifaces := []*models.Interface{}
+	for _, iface := range node.Interfaces {
+		if iface != nil {
+			ifaces = append(ifaces, iface)
+		}
+	}
+	sort.SliceStable(ifaces, func(i, j int) bool {
+		a, b := ifaces[i].Slot, ifaces[j].Slot
+		switch {
+		case a == nil:
+			return false
+		case b == nil:
+			return true
+		}
+		return *a < *b
+	})
+	labels := make([]string, 0, len(ifaces))
+	for _, iface := range ifaces {
+		labels = append(labels, iface.Label)
+	}
+	return labels
+}
+
+// RenderConfig renders the configuration template text with the given data.
+// Besides the standard functions, `join` is available to join lists, as in
+// `{{ join .Tags "," }}`. Referencing missing map keys is an error.
+func RenderConfig(name, text string, data any) (string, error) {
+	tmpl, err := template.New(name).
+		Funcs(template.FuncMap{"join": strings.Join}).
+		Option("missingkey=error").
+		Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var result strings.Builder
+	if err := tmpl.Execute(&result, data); err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
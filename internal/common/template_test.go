@@ -0,0 +1,65 @@
+package common_test
+
+import (
+	"testing"
+
+	"github.com/rschmied/gocmlclient/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+)
+
+func TestHostname(t *testing.T) {
+	assert.Equal(t, "leaf-1", common.Hostname("leaf-1"))
+	assert.Equal(t, "leaf-1", common.Hostname("leaf 1"))
+	assert.Equal(t, "R1-lab", common.Hostname("R1.lab"))
+	assert.Equal(t, "core", common.Hostname("(core)"))
+}
+
+func TestRenderConfig(t *testing.T) {
+	data := common.NewConfigTemplateData("leaf 1", "iosv", []string{"edge", "leaf"})
+
+	result, err := common.RenderConfig("t", "hostname {{ .Hostname }}\n! {{ .Label }} ({{ .NodeDefinition }}) {{ join .Tags \",\" }}", data)
+	require.NoError(t, err)
+	assert.Equal(t, "hostname leaf-1\n! leaf 1 (iosv) edge,leaf", result)
+
+	_, err = common.RenderConfig("t", "{{ .Missing }}", data)
+	assert.Error(t, err)
+
+	_, err = common.RenderConfig("t", "{{ .Label ", data)
+	assert.Error(t, err)
+}
+
+func TestNodeTemplateData(t *testing.T) {
+	slot := func(v int) *int { return &v }
+	node := &models.Node{
+		ID:             "n1",
+		LabID:          "lab1",
+		Label:          "R1",
+		NodeDefinition: "iosv",
+		Interfaces: models.InterfaceList{
+			{Label: "Loopback0"},
+			{Label: "Gi0/1", Slot: slot(1)},
+			{Label: "Gi0/0", Slot: slot(0)},
+		},
+	}
+
+	data := common.NodeTemplateData(node, nil)
+	assert.Equal(t, "n1", data.ID)
+	assert.Equal(t, "lab1", data.LabID)
+	assert.Equal(t, []string{}, data.Tags)
+	assert.Equal(t, []string{"Gi0/0", "Gi0/1", "Loopback0"}, data.Interfaces)
+
+	data = common.NodeTemplateData(node, &models.Lab{ID: "lab2", Title: "demo"})
+	assert.Equal(t, "lab2", data.LabID)
+	assert.Equal(t, "demo", data.LabTitle)
+
+	data.Vars["domain"] = "example.com"
+	result, err := common.RenderConfig("t", "{{ .Hostname }}.{{ .Vars.domain }} {{ index .Interfaces 0 }}", data)
+	require.NoError(t, err)
+	assert.Equal(t, "R1.example.com Gi0/0", result)
+
+	_, err = common.RenderConfig("t", "{{ .Vars.missing }}", data)
+	assert.Error(t, err)
+}
@@ -0,0 +1,316 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/rschmied/gocmlclient/pkg/models"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/topology"
+)
+
+func nodeConfigs(config any) (string, []models.NodeConfig, bool) {
+	switch value := config.(type) {
+	case string:
+		return value, nil, true
+	case []any:
+		configs := []models.NodeConfig{}
+		for _, elem := range value {
+			if nc, ok := elem.(map[string]any); ok {
+				configs = append(configs, models.NodeConfig{
+					Name:    fmt.Sprint(nc["name"]),
+					Content: fmt.Sprint(nc["content"]),
+				})
+			}
+		}
+		return "", configs, true
+	}
+	return "", nil, false
+}
+
+// newTopologyNode converts a topology node into a node which can be created.
+func newTopologyNode(labID models.UUID, node *topology.Node) models.Node {
+	result := models.Node{
+		LabID:          labID,
+		Label:          node.Label,
+		NodeDefinition: node.NodeDefinition,
+		X:              node.X,
+		Y:              node.Y,
+		HideLinks:      node.HideLinks,
+		RAM:            node.RAM,
+		CPUlimit:       node.CPUlimit,
+		DataVolume:     node.DataVolume,
+		BootDiskSize:   node.BootDiskSize,
+		Tags:           []string{},
+	}
+	if node.Tags != nil {
+		result.Tags = node.Tags
+	}
+	if node.CPUs != nil {
+		result.CPUs = *node.CPUs
+	}
+	if node.ImageDefinition != "" {
+		imageDef := node.ImageDefinition
+		result.ImageDefinition = &imageDef
+	}
+	if config, configs, ok := nodeConfigs(node.Configuration); ok {
+		if configs != nil {
+			result.Configurations = configs
+		} else {
+			result.Configuration = config
+		}
+	}
+	return result
+}
+
+// newAnnotationCreate converts a topology annotation into an annotation
+// create request. The topology uses the same attribute names as the API.
+func newAnnotationCreate(annotation map[string]any) (models.AnnotationCreate, error) {
+	create := models.AnnotationCreate{Type: models.AnnotationType(fmt.Sprint(annotation["type"]))}
+
+	var target any
+	switch create.Type {
+	case models.AnnotationTypeText:
+		create.Text = &models.TextAnnotation{}
+		target = create.Text
+	case models.AnnotationTypeRectangle:
+		create.Rectangle = &models.RectangleAnnotation{}
+		target = create.Rectangle
+	case models.AnnotationTypeEllipse:
+		create.Ellipse = &models.EllipseAnnotation{}
+		target = create.Ellipse
+	case models.AnnotationTypeLine:
+		create.Line = &models.LineAnnotation{}
+		target = create.Line
+	default:
+		return create, fmt.Errorf("unsupported annotation type %q", create.Type)
+	}
+
+	data, err := json.Marshal(annotation)
+	if err != nil {
+		return create, err
+	}
+	return create, json.Unmarshal(data, target)
+}
+
+// slotOf returns the slot of the interface with the given ID on the node.
+func slotOf(node *models.Node, ifaceID models.UUID, fallback int) int {
+	if node == nil {
+		return fallback
+	}
+	for _, iface := range node.Interfaces {
+		if iface.ID == ifaceID && iface.Slot != nil {
+			return *iface.Slot
+		}
+	}
+	return fallback
+}
+
+// LinkEndpoints returns the endpoints of a link of the lab as node label and
+// interface slot, e.g. "r1:0". The result is false if a node of the link is
+// not part of the lab.
+func LinkEndpoints(lab *models.Lab, link *models.Link) (string, string, bool) {
+	src, dst := lab.Nodes[link.SrcNode], lab.Nodes[link.DstNode]
+	if src == nil || dst == nil {
+		return "", "", false
+	}
+	return fmt.Sprintf("%s:%d", src.Label, slotOf(src, link.SrcID, link.SrcSlot)),
+		fmt.Sprintf("%s:%d", dst.Label, slotOf(dst, link.DstID, link.DstSlot)), true
+}
+
+// hasLink reports whether the lab has a link with the given ends, in the
+// format of topology.LinkEnds.String(), in either direction.
+func hasLink(lab *models.Lab, ends string) bool {
+	for _, link := range lab.Links {
+		src, dst, ok := LinkEndpoints(lab, link)
+		if ok && (src+"-"+dst == ends || dst+"-"+src == ends) {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyTopologyDiff applies the difference to the lab in place, newTopo is
+// the target topology of the difference. Links are removed first, then
+// removed nodes are stopped, wiped and deleted before nodes are added and
+// updated and links and annotations are added. The nodes of the lab by label
+// are returned, including the added nodes. Nodes and links which already
+// exist in the lab are not created again, so that the difference can be
+// applied again after a partial failure.
+func ApplyTopologyDiff(ctx context.Context, cfg *ProviderConfig, diags *diag.Diagnostics, lab *models.Lab, newTopo *topology.Topology, diff *topology.Diff, timeout string) map[string]*models.Node {
+	var err error
+	client := cfg.Client()
+	labID := lab.ID
+
+	byLabel := map[string]*models.Node{}
+	for _, node := range lab.Nodes {
+		if node != nil {
+			byLabel[node.Label] = node
+		}
+	}
+
+	if diff.Lab {
+		_, err = client.Lab.Update(ctx, labID, models.LabUpdateRequest{
+			Title:       newTopo.Lab.Title,
+			Description: newTopo.Lab.Description,
+			Notes:       newTopo.Lab.Notes,
+		})
+		if err != nil {
+			diags.AddError(ErrorLabel, fmt.Sprintf("Unable to update lab, got error: %s", err))
+			return byLabel
+		}
+	}
+
+	// links go first, links of removed nodes are deleted with the node
+	for _, ends := range diff.RemoveLinks {
+		for _, link := range lab.Links {
+			src, dst, ok := LinkEndpoints(lab, link)
+			if !ok || (src+"-"+dst != ends.String() && dst+"-"+src != ends.String()) {
+				continue
+			}
+			if link.State == models.LinkStateStarted {
+				if err = client.Link.Stop(ctx, labID, link.ID); err != nil {
+					diags.AddError(ErrorLabel, fmt.Sprintf("Unable to stop link %s, got error: %s", ends, err))
+					return byLabel
+				}
+			}
+			if err = client.Link.Delete(ctx, labID, link.ID); err != nil {
+				diags.AddError(ErrorLabel, fmt.Sprintf("Unable to delete link %s, got error: %s", ends, err))
+				return byLabel
+			}
+		}
+	}
+
+	// removed nodes have to be stopped and wiped before they can be deleted
+	stopped := false
+	for _, removed := range diff.RemoveNodes {
+		node := byLabel[removed.Label]
+		if node == nil || node.State == models.NodeStateDefined || node.State == models.NodeStateStopped {
+			continue
+		}
+		if err = client.Node.Stop(ctx, labID, node.ID); err != nil {
+			diags.AddError(ErrorLabel, fmt.Sprintf("Unable to stop node %s, got error: %s", node.Label, err))
+			return byLabel
+		}
+		stopped = true
+	}
+	if stopped {
+		Converge(ctx, client, diags, string(labID), timeout)
+		if diags.HasError() {
+			return byLabel
+		}
+	}
+	for _, removed := range diff.RemoveNodes {
+		node := byLabel[removed.Label]
+		if node == nil {
+			continue
+		}
+		if node.State != models.NodeStateDefined {
+			if err = client.Node.Wipe(ctx, labID, node.ID); err != nil {
+				diags.AddError(ErrorLabel, fmt.Sprintf("Unable to wipe node %s, got error: %s", node.Label, err))
+				return byLabel
+			}
+		}
+		if err = client.Node.Delete(ctx, labID, node.ID); err != nil {
+			diags.AddError(ErrorLabel, fmt.Sprintf("Unable to delete node %s, got error: %s", node.Label, err))
+			return byLabel
+		}
+		delete(byLabel, node.Label)
+	}
+
+	for _, added := range diff.AddNodes {
+		if byLabel[added.Label] != nil {
+			continue
+		}
+		node, createErr := client.Node.Create(ctx, newTopologyNode(labID, added))
+		if createErr != nil {
+			diags.AddError(ErrorLabel, fmt.Sprintf("Unable to create node %s, got error: %s", added.Label, createErr))
+			return byLabel
+		}
+		byLabel[added.Label] = &node
+	}
+
+	for _, update := range diff.UpdateNodes {
+		current := byLabel[update.New.Label]
+		if current == nil {
+			continue
+		}
+		wanted := newTopologyNode(labID, update.New)
+		node := models.Node{
+			ID:             current.ID,
+			LabID:          labID,
+			Label:          current.Label,
+			State:          current.State,
+			NodeDefinition: current.NodeDefinition,
+			X:              wanted.X,
+			Y:              wanted.Y,
+			HideLinks:      wanted.HideLinks,
+			Tags:           wanted.Tags,
+		}
+		if update.Hardware {
+			node.RAM = wanted.RAM
+			node.CPUs = wanted.CPUs
+			node.CPUlimit = wanted.CPUlimit
+			node.DataVolume = wanted.DataVolume
+			node.BootDiskSize = wanted.BootDiskSize
+		}
+		if _, err = client.Node.Update(ctx, node); err != nil {
+			diags.AddError(ErrorLabel, fmt.Sprintf("Unable to update node %s, got error: %s", current.Label, err))
+			return byLabel
+		}
+		if !update.Configuration {
+			continue
+		}
+		if wanted.Configurations != nil {
+			err = client.Node.SetNamedConfigs(ctx, current, wanted.Configurations)
+		} else if config, ok := wanted.Configuration.(string); ok {
+			err = client.Node.SetConfig(ctx, current, config)
+		}
+		if err != nil {
+			diags.AddError(ErrorLabel, fmt.Sprintf("Unable to set configuration of node %s, got error: %s", current.Label, err))
+			return byLabel
+		}
+	}
+
+	// see the link resource, links must be created sequentially
+	cfg.Lock()
+	defer cfg.Unlock()
+	for _, ends := range diff.AddLinks {
+		if hasLink(lab, ends.String()) {
+			continue
+		}
+		nodeA, nodeB := byLabel[ends.NodeA], byLabel[ends.NodeB]
+		if nodeA == nil || nodeB == nil {
+			diags.AddError(ErrorLabel, fmt.Sprintf("Unable to create link %s, node not found", ends))
+			return byLabel
+		}
+		_, err = client.Link.Create(ctx, models.Link{
+			LabID:   labID,
+			Label:   ends.Label,
+			SrcNode: nodeA.ID,
+			DstNode: nodeB.ID,
+			SrcSlot: ends.SlotA,
+			DstSlot: ends.SlotB,
+		})
+		if err != nil {
+			diags.AddError(ErrorLabel, fmt.Sprintf("Unable to create link %s, got error: %s", ends, err))
+			return byLabel
+		}
+	}
+
+	// annotations can't be matched against the lab, they are not retried to
+	// avoid duplicates
+	for _, annotation := range diff.AddAnnotations {
+		create, convErr := newAnnotationCreate(annotation)
+		if convErr != nil {
+			diags.AddWarning(ErrorLabel, fmt.Sprintf("Unable to convert annotation, it is not added: %s", convErr))
+			continue
+		}
+		if _, err = client.Annotation.Create(ctx, labID, create); err != nil {
+			diags.AddWarning(ErrorLabel, fmt.Sprintf("Unable to create annotation, it is not added: %s", err))
+		}
+	}
+	return byLabel
+}
@@ -0,0 +1,358 @@
+// Package layout computes canvas coordinates for the nodes of a lab from the
+// link graph. Nodes with fixed coordinates are never moved, the other nodes
+// are placed around (grid and hierarchical: below) them. Nodes created
+// without coordinates are at the origin, which is therefore never used as a
+// computed position.
+package layout
+
+import (
+	"math"
+	"sort"
+)
+
+// Point is a position on the canvas.
+type Point struct {
+	X, Y int
+}
+
+// Unplaced reports whether a node at the given position has been created
+// without coordinates.
+func Unplaced(x, y int) bool {
+	return x == 0 && y == 0
+}
+
+// position returns the point for a computed position, the origin is avoided.
+func position(x, y int) Point {
+	if Unplaced(x, y) {
+		return Point{X: 1}
+	}
+	return Point{X: x, Y: y}
+}
+
+// Node is a node of the graph, the position of fixed nodes is kept.
+type Node struct {
+	ID    string
+	Label string
+	Tags  []string
+	Fixed bool
+	X, Y  int
+}
+
+// Graph is the link graph of a lab, edges connect node IDs.
+type Graph struct {
+	Nodes []Node
+	Edges [][2]string
+}
+
+// free returns the nodes which are not fixed, ordered by label.
+func (g *Graph) free() []*Node {
+	result := []*Node{}
+	for idx := range g.Nodes {
+		if !g.Nodes[idx].Fixed {
+			result = append(result, &g.Nodes[idx])
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Label < result[j].Label
+	})
+	return result
+}
+
+// neighbors returns the adjacency of the graph, the neighbors of each node
+// are ordered by label.
+func (g *Graph) neighbors() map[string][]string {
+	labels := map[string]string{}
+	for _, node := range g.Nodes {
+		labels[node.ID] = node.Label
+	}
+	result := map[string][]string{}
+	for _, edge := range g.Edges {
+		if _, ok := labels[edge[0]]; !ok {
+			continue
+		}
+		if _, ok := labels[edge[1]]; !ok || edge[0] == edge[1] {
+			continue
+		}
+		result[edge[0]] = append(result[edge[0]], edge[1])
+		result[edge[1]] = append(result[edge[1]], edge[0])
+	}
+	for id := range result {
+		sort.SliceStable(result[id], func(i, j int) bool {
+			return labels[result[id][i]] < labels[result[id][j]]
+		})
+	}
+	return result
+}
+
+// origin returns the top left position for the free nodes: below the fixed
+// nodes if there are any, one spacing away from the origin otherwise.
+func (g *Graph) origin(spacing int) Point {
+	found := false
+	result := Point{X: spacing, Y: spacing}
+	for _, node := range g.Nodes {
+		if !node.Fixed {
+			continue
+		}
+		if !found || node.X < result.X {
+			result.X = node.X
+		}
+		if !found || node.Y+spacing > result.Y {
+			result.Y = node.Y + spacing
+		}
+		found = true
+	}
+	return result
+}
+
+// bfsOrder returns the free nodes in breadth first order of the link graph,
+// starting each connected component at its node with the lowest label. This
+// keeps connected nodes close to each other.
+func (g *Graph) bfsOrder() []*Node {
+	free := g.free()
+	byID := map[string]*Node{}
+	for _, node := range free {
+		byID[node.ID] = node
+	}
+	adjacent := g.neighbors()
+	seen := map[string]bool{}
+	result := []*Node{}
+	for _, root := range free {
+		if seen[root.ID] {
+			continue
+		}
+		seen[root.ID] = true
+		queue := []string{root.ID}
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			result = append(result, byID[id])
+			for _, next := range adjacent[id] {
+				if _, ok := byID[next]; ok && !seen[next] {
+					seen[next] = true
+					queue = append(queue, next)
+				}
+			}
+		}
+	}
+	return result
+}
+
+// Grid places the free nodes on a grid in breadth first order of the link
+// graph, the grid has about as many columns as rows.
+func Grid(g Graph, spacing int) map[string]Point {
+	nodes := g.bfsOrder()
+	result := map[string]Point{}
+	if len(nodes) == 0 {
+		return result
+	}
+	origin := g.origin(spacing)
+	cols := int(math.Ceil(math.Sqrt(float64(len(nodes)))))
+	for idx, node := range nodes {
+		result[node.ID] = position(origin.X+(idx%cols)*spacing, origin.Y+(idx/cols)*spacing)
+	}
+	return result
+}
+
+// tier returns the index of the first tier matching a tag of the node, nodes
+// without a matching tag go to the last tier.
+func tier(node *Node, tiers []string) int {
+	for idx, name := range tiers {
+		for _, tag := range node.Tags {
+			if tag == name {
+				return idx
+			}
+		}
+	}
+	return len(tiers)
+}
+
+// depths returns the breadth first depth of the free nodes, each connected
+// component starts at its node with the most links.
+func (g *Graph) depths() map[string]int {
+	nodes := g.free()
+	adjacent := g.neighbors()
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return len(adjacent[nodes[i].ID]) > len(adjacent[nodes[j].ID])
+	})
+	free := map[string]bool{}
+	for _, node := range nodes {
+		free[node.ID] = true
+	}
+	result := map[string]int{}
+	for _, root := range nodes {
+		if _, ok := result[root.ID]; ok {
+			continue
+		}
+		result[root.ID] = 0
+		queue := []string{root.ID}
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			for _, next := range adjacent[id] {
+				if _, ok := result[next]; !ok && free[next] {
+					result[next] = result[id] + 1
+					queue = append(queue, next)
+				}
+			}
+		}
+	}
+	return result
+}
+
+// Hierarchical places the free nodes in rows. With tiers, the row of a node
+// is given by the first tier matching one of its tags, nodes without a
+// matching tag go to the last row. Without tiers, the row is the distance in
+// the link graph from the best connected node. Within a row, nodes are
+// ordered by the position of their neighbors in the rows above.
+func Hierarchical(g Graph, tiers []string, spacing int) map[string]Point {
+	nodes := g.free()
+	result := map[string]Point{}
+	if len(nodes) == 0 {
+		return result
+	}
+
+	rows := map[int][]*Node{}
+	if len(tiers) > 0 {
+		for _, node := range nodes {
+			idx := tier(node, tiers)
+			rows[idx] = append(rows[idx], node)
+		}
+	} else {
+		depths := g.depths()
+		for _, node := range nodes {
+			rows[depths[node.ID]] = append(rows[depths[node.ID]], node)
+		}
+	}
+	indexes := make([]int, 0, len(rows))
+	widest := 0
+	for idx, row := range rows {
+		indexes = append(indexes, idx)
+		widest = max(widest, len(row))
+	}
+	sort.Ints(indexes)
+
+	origin := g.origin(spacing)
+	adjacent := g.neighbors()
+	for y, idx := range indexes {
+		row := rows[idx]
+		// barycenter of the neighbors which are already placed, nodes
+		// without placed neighbors keep their label order
+		center := map[string]float64{}
+		for pos, node := range row {
+			sum, count := 0, 0
+			for _, next := range adjacent[node.ID] {
+				if p, ok := result[next]; ok {
+					sum += p.X
+					count++
+				}
+			}
+			if count > 0 {
+				center[node.ID] = float64(sum) / float64(count)
+			} else {
+				center[node.ID] = float64(origin.X + pos*spacing)
+			}
+		}
+		sort.SliceStable(row, func(i, j int) bool {
+			return center[row[i].ID] < center[row[j].ID]
+		})
+		offset := (widest - len(row)) * spacing / 2
+		for x, node := range row {
+			result[node.ID] = position(origin.X+offset+x*spacing, origin.Y+y*spacing)
+		}
+	}
+	return result
+}
+
+// forceIterations is the number of simulation steps of Force.
+const forceIterations = 300
+
+// Force places the free nodes using a force-directed (Fruchterman-Reingold)
+// simulation: linked nodes attract each other, all nodes repel each other.
+// Fixed nodes take part in the simulation but are not moved. The result is
+// deterministic, free nodes start on a circle in label order.
+func Force(g Graph, spacing int) map[string]Point {
+	free := g.free()
+	result := map[string]Point{}
+	if len(free) == 0 {
+		return result
+	}
+
+	type vec struct{ x, y float64 }
+	pos := map[string]vec{}
+	fixed := map[string]bool{}
+	var cx, cy float64
+	for _, node := range g.Nodes {
+		if node.Fixed {
+			pos[node.ID] = vec{float64(node.X), float64(node.Y)}
+			fixed[node.ID] = true
+			cx += float64(node.X)
+			cy += float64(node.Y)
+		}
+	}
+	if len(fixed) > 0 {
+		cx /= float64(len(fixed))
+		cy /= float64(len(fixed))
+	}
+	radius := float64(spacing) * math.Sqrt(float64(len(g.Nodes)))
+	for idx, node := range free {
+		angle := 2 * math.Pi * float64(idx) / float64(len(free))
+		pos[node.ID] = vec{cx + radius*math.Cos(angle), cy + radius*math.Sin(angle)}
+	}
+
+	ids := make([]string, 0, len(g.Nodes))
+	for _, node := range g.Nodes {
+		ids = append(ids, node.ID)
+	}
+	sort.Strings(ids)
+	adjacent := g.neighbors()
+
+	k := float64(spacing)
+	temperature := radius
+	for step := 0; step < forceIterations; step++ {
+		disp := map[string]vec{}
+		for i, a := range ids {
+			for _, b := range ids[i+1:] {
+				dx, dy := pos[a].x-pos[b].x, pos[a].y-pos[b].y
+				dist := math.Max(math.Hypot(dx, dy), 0.01)
+				force := k * k / dist
+				da, db := disp[a], disp[b]
+				da.x += dx / dist * force
+				da.y += dy / dist * force
+				db.x -= dx / dist * force
+				db.y -= dy / dist * force
+				disp[a], disp[b] = da, db
+			}
+		}
+		for _, a := range ids {
+			for _, b := range adjacent[a] {
+				// each edge is visited from both ends
+				dx, dy := pos[a].x-pos[b].x, pos[a].y-pos[b].y
+				dist := math.Max(math.Hypot(dx, dy), 0.01)
+				force := dist * dist / k
+				da := disp[a]
+				da.x -= dx / dist * force
+				da.y -= dy / dist * force
+				disp[a] = da
+			}
+		}
+		for _, id := range ids {
+			if fixed[id] {
+				continue
+			}
+			d := disp[id]
+			length := math.Max(math.Hypot(d.x, d.y), 0.01)
+			move := math.Min(length, temperature)
+			p := pos[id]
+			p.x += d.x / length * move
+			p.y += d.y / length * move
+			pos[id] = p
+		}
+		temperature = math.Max(temperature*0.97, 1)
+	}
+
+	for _, node := range free {
+		p := pos[node.ID]
+		result[node.ID] = position(int(math.Round(p.x)), int(math.Round(p.y)))
+	}
+	return result
+}
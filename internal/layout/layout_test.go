@@ -0,0 +1,87 @@
+package layout
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// star returns a graph with a center node linked to count leaf nodes, the
+// center is tagged core and the leaves edge.
+func star(count int) Graph {
+	g := Graph{Nodes: []Node{{ID: "c", Label: "center", Tags: []string{"core"}}}}
+	for idx := range count {
+		id := fmt.Sprintf("l%d", idx)
+		g.Nodes = append(g.Nodes, Node{ID: id, Label: "leaf-" + id, Tags: []string{"edge"}})
+		g.Edges = append(g.Edges, [2]string{"c", id})
+	}
+	return g
+}
+
+func distinct(t *testing.T, points map[string]Point) {
+	t.Helper()
+	seen := map[Point]string{}
+	for id, p := range points {
+		assert.False(t, Unplaced(p.X, p.Y), "node %s at the origin", id)
+		other, ok := seen[p]
+		assert.False(t, ok, "nodes %s and %s at the same position", id, other)
+		seen[p] = id
+	}
+}
+
+func TestGrid(t *testing.T) {
+	points := Grid(star(4), 100)
+	assert.Len(t, points, 5)
+	distinct(t, points)
+	// breadth first order from the lowest label, 3 columns
+	assert.Equal(t, Point{100, 100}, points["c"])
+	assert.Equal(t, Point{200, 100}, points["l0"])
+	assert.Equal(t, Point{100, 200}, points["l2"])
+}
+
+func TestGridFixed(t *testing.T) {
+	g := star(2)
+	g.Nodes[0].Fixed = true
+	g.Nodes[0].X, g.Nodes[0].Y = -50, 300
+	points := Grid(g, 100)
+	assert.Len(t, points, 2)
+	assert.NotContains(t, points, "c")
+	// placed below the fixed nodes
+	assert.Equal(t, Point{-50, 400}, points["l0"])
+	assert.Equal(t, Point{50, 400}, points["l1"])
+}
+
+func TestHierarchical(t *testing.T) {
+	points := Hierarchical(star(3), []string{"core", "edge"}, 100)
+	assert.Len(t, points, 4)
+	distinct(t, points)
+	assert.Equal(t, 100, points["c"].Y)
+	for _, id := range []string{"l0", "l1", "l2"} {
+		assert.Equal(t, 200, points[id].Y)
+	}
+	// the single node of the top row is centered above the leaves
+	assert.Equal(t, points["l1"].X, points["c"].X)
+
+	// without tiers, the best connected node goes to the top
+	points = Hierarchical(star(3), nil, 100)
+	assert.Equal(t, 100, points["c"].Y)
+	assert.Equal(t, 200, points["l0"].Y)
+}
+
+func TestForce(t *testing.T) {
+	g := star(5)
+	g.Nodes = append(g.Nodes, Node{ID: "x", Label: "x", Fixed: true, X: 1000, Y: 1000})
+	points := Force(g, 100)
+	assert.Len(t, points, 6)
+	assert.NotContains(t, points, "x")
+	distinct(t, points)
+	assert.Equal(t, points, Force(g, 100), "result is deterministic")
+
+	// leaves are closer to the center than to each other on average
+	dist := func(a, b Point) int {
+		dx, dy := a.X-b.X, a.Y-b.Y
+		return dx*dx + dy*dy
+	}
+	assert.Less(t, dist(points["c"], points["l0"]), dist(points["l0"], points["l2"]))
+}
@@ -24,6 +24,8 @@ import (
 	r_lifecycle "github.com/ciscodevnet/terraform-provider-cml2/internal/provider/resource/lifecycle"
 	r_link "github.com/ciscodevnet/terraform-provider-cml2/internal/provider/resource/link"
 	r_node "github.com/ciscodevnet/terraform-provider-cml2/internal/provider/resource/node"
+	r_nodestate "github.com/ciscodevnet/terraform-provider-cml2/internal/provider/resource/nodestate"
+	r_topology "github.com/ciscodevnet/terraform-provider-cml2/internal/provider/resource/topology"
 	r_user "github.com/ciscodevnet/terraform-provider-cml2/internal/provider/resource/user"
 
 	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
@@ -120,6 +122,8 @@ func (p *CML2Provider) Resources(ctx context.Context) []func() resource.Resource
 		r_lifecycle.NewResource,
 		r_link.NewResource,
 		r_node.NewResource,
+		r_nodestate.NewResource,
+		r_topology.NewResource,
 		r_annotation.NewResource,
 		r_group.NewResource,
 		r_user.NewResource,
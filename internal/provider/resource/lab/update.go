@@ -34,6 +34,14 @@ func (r LabResource) Update(ctx context.Context, req resource.UpdateRequest, res
 		Notes:       data.Notes.ValueString(),
 	}
 
+	// the auto stop deadline of a lifecycle is recorded in the notes but not
+	// managed by the lab resource, keep it
+	if current, err := r.cfg.Client().Lab.GetByID(ctx, models.UUID(data.ID.ValueString()), false); err == nil {
+		if deadline, ok := cmlschema.AutoStopDeadline(current.Notes); ok {
+			updateReq.Notes = cmlschema.WithAutoStopDeadline(updateReq.Notes, deadline)
+		}
+	}
+
 	if ns := expandNodeStaging(ctx, data.NodeStaging, &resp.Diagnostics); ns != nil {
 		updateReq.NodeStaging = ns
 	}
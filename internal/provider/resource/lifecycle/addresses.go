@@ -0,0 +1,162 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/rschmied/gocmlclient/pkg/models"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+)
+
+// sortedInterfaces returns the running interfaces of the node ordered by
+// slot, interfaces without a slot (loopbacks) go last.
+func sortedInterfaces(node *models.Node) []*models.Interface {
+	ifaces := []*models.Interface{}
+	for _, iface := range node.Interfaces {
+		if iface != nil && iface.Runs() {
+			ifaces = append(ifaces, iface)
+		}
+	}
+	sort.SliceStable(ifaces, func(i, j int) bool {
+		a, b := ifaces[i].Slot, ifaces[j].Slot
+		switch {
+		case a == nil:
+			return false
+		case b == nil:
+			return true
+		}
+		return *a < *b
+	})
+	return ifaces
+}
+
+// nodeAddresses computes the addresses of all nodes by node label and
+// interface label as well as the management address of each node: the first
+// reachable IPv4 address of the selected interfaces in slot order, or the
+// first reachable IPv6 address if there is no IPv4 address. The selector
+// limits the interfaces considered for the management address, nil means all
+// interfaces.
+func nodeAddresses(lab *models.Lab, selector *regexp.Regexp) (map[string]map[string][]string, map[string]string) {
+	addresses := map[string]map[string][]string{}
+	management := map[string]string{}
+
+	for _, node := range lab.Nodes {
+		if node == nil {
+			continue
+		}
+		ifaces := map[string][]string{}
+		var ip4, ip6 string
+		for _, iface := range sortedInterfaces(node) {
+			if len(iface.IP4)+len(iface.IP6) == 0 {
+				continue
+			}
+			list := append(append([]string{}, iface.IP4...), iface.IP6...)
+			ifaces[iface.Label] = list
+
+			if selector != nil && !selector.MatchString(iface.Label) {
+				continue
+			}
+			for _, address := range iface.IP4 {
+				if ip, ok := common.ReachableAddress(address); ok && ip4 == "" {
+					ip4 = ip
+				}
+			}
+			for _, address := range iface.IP6 {
+				if ip, ok := common.ReachableAddress(address); ok && ip6 == "" {
+					ip6 = ip
+				}
+			}
+		}
+		addresses[node.Label] = ifaces
+		switch {
+		case ip4 != "":
+			management[node.Label] = ip4
+		case ip6 != "":
+			management[node.Label] = ip6
+		}
+	}
+	return addresses, management
+}
+
+// populateAddresses sets the addresses and management_ip attributes from the
+// given lab.
+func populateAddresses(ctx context.Context, lab *models.Lab, data *cmlschema.LabLifecycleModel, diags *diag.Diagnostics) {
+	var selector *regexp.Regexp
+	if expr := data.ManagementInterface.ValueString(); expr != "" {
+		var err error
+		selector, err = regexp.Compile(expr)
+		if err != nil {
+			diags.AddError(common.ErrorLabel, fmt.Sprintf("Unable to compile management interface selector, got error: %s", err))
+			return
+		}
+	}
+
+	addresses, management := nodeAddresses(lab, selector)
+
+	var d diag.Diagnostics
+	data.Addresses, d = types.MapValueFrom(
+		ctx,
+		types.MapType{ElemType: types.ListType{ElemType: types.StringType}},
+		addresses,
+	)
+	diags.Append(d...)
+	data.ManagementIP, d = types.MapValueFrom(ctx, types.StringType, management)
+	diags.Append(d...)
+}
+
+func getWaitForAddresses(ctx context.Context, config attributeGetter, diags *diag.Diagnostics) *labLifecycleWaitForAddresses {
+	var wait *labLifecycleWaitForAddresses
+	diags.Append(config.GetAttribute(ctx, path.Root("wait_for_addresses"), &wait)...)
+	return wait
+}
+
+// selector returns the interfaces to wait for, either the configured ones or
+// all interfaces connected to external connectors.
+func (w *labLifecycleWaitForAddresses) selector(ctx context.Context, diags *diag.Diagnostics) common.InterfaceSelector {
+	if w.Interfaces.IsNull() || w.Interfaces.IsUnknown() {
+		return func(lab *models.Lab) ([]common.InterfaceRef, error) {
+			return common.ExternalInterfaces(lab), nil
+		}
+	}
+	var ifaces map[string][]string
+	diags.Append(w.Interfaces.ElementsAs(ctx, &ifaces, false)...)
+	refs := []common.InterfaceRef{}
+	for node, labels := range ifaces {
+		for _, label := range labels {
+			refs = append(refs, common.InterfaceRef{Node: node, Interface: label})
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].String() < refs[j].String()
+	})
+	return func(*models.Lab) ([]common.InterfaceRef, error) {
+		return refs, nil
+	}
+}
+
+// waitForAddresses waits for the configured interfaces to report addresses,
+// the timeout defaults to the given operation timeout.
+func (r *LabLifecycleResource) waitForAddresses(ctx context.Context, diags *diag.Diagnostics, id string, wait *labLifecycleWaitForAddresses, timeout string) {
+	if wait == nil {
+		return
+	}
+	if !wait.Timeout.IsNull() && wait.Timeout.ValueString() != "" {
+		timeout = wait.Timeout.ValueString()
+	}
+	family := cmlschema.AddressFamilyAny
+	if !wait.AddressFamily.IsNull() {
+		family = wait.AddressFamily.ValueString()
+	}
+	selector := wait.selector(ctx, diags)
+	if diags.HasError() {
+		return
+	}
+	common.WaitForAddresses(ctx, r.cfg.Client(), diags, id, selector, family, timeout)
+}
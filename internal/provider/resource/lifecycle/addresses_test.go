@@ -0,0 +1,51 @@
+package lifecycle
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/rschmied/gocmlclient/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeAddresses(t *testing.T) {
+	slot := func(n int) *int { return &n }
+	lab := &models.Lab{
+		Nodes: models.NodeMap{
+			"n1": {
+				ID:    "n1",
+				Label: "r1",
+				Interfaces: models.InterfaceList{
+					{Label: "Loopback0", State: models.IfaceStateStarted, IP4: []string{"10.255.0.1"}},
+					{Label: "Gi0/1", Slot: slot(1), State: models.IfaceStateStarted, IP4: []string{"192.168.1.1"}},
+					{Label: "Gi0/0", Slot: slot(0), State: models.IfaceStateStarted, IP4: []string{"169.254.1.1"}, IP6: []string{"fe80::1", "2001:db8::1/64"}},
+				},
+			},
+			"n2": {
+				ID:    "n2",
+				Label: "r2",
+				Interfaces: models.InterfaceList{
+					{Label: "eth0", Slot: slot(0), State: models.IfaceStateDefined, IP4: []string{"192.168.1.2"}},
+				},
+			},
+		},
+	}
+
+	addresses, management := nodeAddresses(lab, nil)
+	assert.Equal(t, map[string]map[string][]string{
+		"r1": {
+			"Loopback0": {"10.255.0.1"},
+			"Gi0/0":     {"169.254.1.1", "fe80::1", "2001:db8::1/64"},
+			"Gi0/1":     {"192.168.1.1"},
+		},
+		"r2": {},
+	}, addresses)
+	// IPv4 preferred, link-local skipped, slot order
+	assert.Equal(t, map[string]string{"r1": "192.168.1.1"}, management)
+
+	_, management = nodeAddresses(lab, regexp.MustCompile(`^Gi0/0$`))
+	assert.Equal(t, map[string]string{"r1": "2001:db8::1"}, management)
+
+	_, management = nodeAddresses(lab, regexp.MustCompile(`^eth`))
+	assert.Empty(t, management)
+}
@@ -0,0 +1,156 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/rschmied/gocmlclient/pkg/models"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+)
+
+// autoStopExpired reports whether the lab is past the auto stop deadline
+// recorded in state. A changed auto_stop_after sets a new deadline, the old
+// one doesn't apply anymore.
+func autoStopExpired(state, config *cmlschema.LabLifecycleModel, now time.Time) bool {
+	if config.AutoStopAfter.IsNull() || !config.AutoStopAfter.Equal(state.AutoStopAfter) {
+		return false
+	}
+	if state.AutoStopDeadline.IsNull() || state.AutoStopDeadline.IsUnknown() {
+		return false
+	}
+	deadline, err := time.Parse(time.RFC3339, state.AutoStopDeadline.ValueString())
+	if err != nil {
+		return false
+	}
+	return now.After(deadline)
+}
+
+// autoStopRenew reports whether starting the lab sets a new deadline: when
+// the lab is not running yet, auto_stop_after changed or there is no
+// deadline.
+func autoStopRenew(state, config *cmlschema.LabLifecycleModel) bool {
+	return state.State.ValueString() != string(models.LabStateStarted) ||
+		!config.AutoStopAfter.Equal(state.AutoStopAfter) ||
+		state.AutoStopDeadline.IsNull() || state.AutoStopDeadline.IsUnknown()
+}
+
+// planAutoStop plans the auto stop deadline. Past the deadline, a running lab
+// is planned to be STOPPED unless the state is configured explicitly, a lab
+// stopped that way stays stopped.
+func planAutoStop(diags *diag.Diagnostics, config, state, plan *cmlschema.LabLifecycleModel, noState bool, now time.Time) {
+	if config.AutoStopAfter.IsNull() {
+		plan.AutoStopDeadline = types.StringNull()
+		return
+	}
+	if config.AutoStopAfter.IsUnknown() {
+		plan.AutoStopDeadline = types.StringUnknown()
+		return
+	}
+	if noState {
+		plan.AutoStopDeadline = types.StringNull()
+		if plan.State.ValueString() == string(models.LabStateStarted) {
+			plan.AutoStopDeadline = types.StringUnknown()
+		}
+		return
+	}
+
+	current := state.State.ValueString()
+	if autoStopExpired(state, config, now) && current != string(models.LabStateDefined) {
+		switch {
+		case config.State.IsNull():
+			if current == string(models.LabStateStarted) {
+				diags.AddAttributeWarning(
+					path.Root("state"),
+					"Lab auto stop deadline passed",
+					fmt.Sprintf(
+						"The lab was due to stop at %s (auto_stop_after %s), it will be stopped. Change \"auto_stop_after\" to start the lab again with a new deadline.",
+						state.AutoStopDeadline.ValueString(), config.AutoStopAfter.ValueString(),
+					),
+				)
+			}
+			plan.State = types.StringValue(string(models.LabStateStopped))
+		case current == string(models.LabStateStarted) && plan.State.ValueString() == string(models.LabStateStarted):
+			diags.AddAttributeWarning(
+				path.Root("state"),
+				"Lab auto stop deadline passed",
+				fmt.Sprintf(
+					"The lab was due to stop at %s (auto_stop_after %s). It keeps running as \"state\" is configured, remove \"state\" to stop it automatically.",
+					state.AutoStopDeadline.ValueString(), config.AutoStopAfter.ValueString(),
+				),
+			)
+		}
+	}
+
+	plan.AutoStopDeadline = state.AutoStopDeadline
+	if plan.State.ValueString() == string(models.LabStateStarted) && autoStopRenew(state, config) {
+		plan.AutoStopDeadline = types.StringUnknown()
+	}
+}
+
+// applyAutoStop records the auto stop deadline of a running lab in the lab
+// notes. A new deadline is set if the planned deadline is unknown, otherwise
+// the planned deadline is kept.
+func (r *LabLifecycleResource) applyAutoStop(ctx context.Context, diags *diag.Diagnostics, lab *models.Lab, data *cmlschema.LabLifecycleModel) {
+	if data.AutoStopAfter.IsNull() {
+		data.AutoStopDeadline = types.StringNull()
+		return
+	}
+	if lab.State != models.LabStateStarted {
+		if data.AutoStopDeadline.IsUnknown() {
+			data.AutoStopDeadline = types.StringNull()
+		}
+		return
+	}
+
+	var deadline time.Time
+	if data.AutoStopDeadline.IsUnknown() || data.AutoStopDeadline.IsNull() {
+		after, err := time.ParseDuration(data.AutoStopAfter.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("auto_stop_after"), common.ErrorLabel, err.Error())
+			return
+		}
+		deadline = time.Now().Add(after).UTC().Truncate(time.Second)
+	} else {
+		var err error
+		deadline, err = time.Parse(time.RFC3339, data.AutoStopDeadline.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("auto_stop_deadline"), common.ErrorLabel, err.Error())
+			return
+		}
+	}
+	data.AutoStopDeadline = types.StringValue(deadline.Format(time.RFC3339))
+
+	if recorded, ok := cmlschema.AutoStopDeadline(lab.Notes); ok && recorded.Equal(deadline) {
+		return
+	}
+	tflog.Info(ctx, "recording auto stop deadline", map[string]any{"lab": lab.ID, "deadline": data.AutoStopDeadline.ValueString()})
+	_, err := r.cfg.Client().Lab.Update(ctx, lab.ID, models.LabUpdateRequest{
+		Title:       lab.Title,
+		Description: lab.Description,
+		Notes:       cmlschema.WithAutoStopDeadline(lab.Notes, deadline),
+	})
+	if err != nil {
+		diags.AddError(
+			common.ErrorLabel,
+			fmt.Sprintf("Unable to record auto stop deadline in lab notes, got error: %s", err),
+		)
+	}
+}
+
+// readAutoStop refreshes the auto stop deadline from the lab notes, it can be
+// extended outside of Terraform.
+func readAutoStop(lab *models.Lab, data *cmlschema.LabLifecycleModel) {
+	if data.AutoStopAfter.IsNull() {
+		return
+	}
+	if deadline, ok := cmlschema.AutoStopDeadline(lab.Notes); ok {
+		data.AutoStopDeadline = types.StringValue(deadline.UTC().Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,73 @@
+package lifecycle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+)
+
+func TestPlanAutoStop(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	model := func(state, after, deadline string) *cmlschema.LabLifecycleModel {
+		m := &cmlschema.LabLifecycleModel{
+			State:            types.StringNull(),
+			AutoStopAfter:    types.StringNull(),
+			AutoStopDeadline: types.StringNull(),
+		}
+		if state != "" {
+			m.State = types.StringValue(state)
+		}
+		if after != "" {
+			m.AutoStopAfter = types.StringValue(after)
+		}
+		if deadline != "" {
+			m.AutoStopDeadline = types.StringValue(deadline)
+		}
+		return m
+	}
+	past, future := "2026-10-19T10:00:00Z", "2026-10-19T14:00:00Z"
+
+	tests := []struct {
+		name         string
+		config       *cmlschema.LabLifecycleModel
+		state        *cmlschema.LabLifecycleModel
+		noState      bool
+		wantState    string
+		wantDeadline types.String
+		warnings     int
+	}{
+		{"not configured", model("", "", ""), model("STARTED", "", ""), false, "STARTED", types.StringNull(), 0},
+		{"create started", model("", "8h", ""), nil, true, "STARTED", types.StringUnknown(), 0},
+		{"create defined", model("DEFINED_ON_CORE", "8h", ""), nil, true, "DEFINED_ON_CORE", types.StringNull(), 0},
+		{"in time", model("", "8h", ""), model("STARTED", "8h", future), false, "STARTED", types.StringValue(future), 0},
+		{"overdue", model("", "8h", ""), model("STARTED", "8h", past), false, "STOPPED", types.StringValue(past), 1},
+		{"stays stopped", model("", "8h", ""), model("STOPPED", "8h", past), false, "STOPPED", types.StringValue(past), 0},
+		{"explicit state", model("STARTED", "8h", ""), model("STARTED", "8h", past), false, "STARTED", types.StringValue(past), 1},
+		{"changed duration", model("", "9h", ""), model("STOPPED", "8h", past), false, "STARTED", types.StringUnknown(), 0},
+		{"started again", model("STARTED", "8h", ""), model("STOPPED", "8h", past), false, "STARTED", types.StringUnknown(), 0},
+		{"wiped lab", model("", "8h", ""), model("DEFINED_ON_CORE", "8h", past), false, "STARTED", types.StringUnknown(), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			plan := *tt.config
+			if plan.State.IsNull() {
+				plan.State = types.StringValue("STARTED")
+			}
+			state := tt.state
+			if state == nil {
+				state = model("", "", "")
+			}
+			planAutoStop(&diags, tt.config, state, &plan, tt.noState, now)
+			assert.False(t, diags.HasError())
+			assert.Equal(t, tt.warnings, diags.WarningsCount())
+			assert.Equal(t, tt.wantState, plan.State.ValueString())
+			assert.Equal(t, tt.wantDeadline, plan.AutoStopDeadline)
+		})
+	}
+}
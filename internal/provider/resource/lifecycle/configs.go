@@ -0,0 +1,125 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/rschmied/gocmlclient/pkg/models"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/topology"
+)
+
+// nodeConfig is the configuration of a node as selected from configs or
+// named_configs, templates are already rendered.
+type nodeConfig struct {
+	key    string
+	config string
+	named  []models.NodeConfig
+}
+
+// configTarget identifies the node a configuration is resolved for, data
+// holds the values available to templates.
+type configTarget struct {
+	id   models.UUID
+	data common.ConfigTemplateData
+}
+
+func sortedKeys(elements map[string]attr.Value) []string {
+	keys := make([]string, 0, len(elements))
+	for key := range elements {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// configKey returns the key of configs, named_configs or config_templates
+// which applies to the node: its label or ID, otherwise a glob pattern or tag
+// key (see selectKey).
+func configKey(node configTarget, keys []string) (string, bool) {
+	if slices.Contains(keys, node.data.Label) {
+		return node.data.Label, true
+	}
+	if node.id != "" && slices.Contains(keys, string(node.id)) {
+		return string(node.id), true
+	}
+	return selectKey(node.data.Label, node.data.Tags, keys)
+}
+
+// renderNodeConfig renders the configuration template for the node. Unless
+// always is set, configurations given for a node label are used as is.
+func renderNodeConfig(key, text string, node configTarget, always bool) (string, error) {
+	if !always && !isSelectorKey(key) {
+		return text, nil
+	}
+	result, err := common.RenderConfig(key, text, node.data)
+	if err != nil {
+		return "", fmt.Errorf("rendering configuration %q for node %s: %w", key, node.data.Label, err)
+	}
+	return result, nil
+}
+
+// resolveNodeConfig returns the configuration from configs, named_configs or
+// config_templates which applies to the node. The result is false if there
+// is none.
+func resolveNodeConfig(ctx context.Context, data *cmlschema.LabLifecycleModel, node configTarget, diags *diag.Diagnostics) (nodeConfig, bool) {
+	vars := map[string]string{}
+	if !data.ConfigVars.IsNull() && !data.ConfigVars.IsUnknown() {
+		diags.Append(data.ConfigVars.ElementsAs(ctx, &vars, false)...)
+	}
+	node.data.Vars = vars
+
+	for _, attr := range []struct {
+		name   string
+		value  types.Map
+		always bool
+	}{
+		{"configs", data.Configs, false},
+		{"config_templates", data.ConfigTemplates, true},
+	} {
+		key, ok := configKey(node, sortedKeys(attr.value.Elements()))
+		if !ok {
+			continue
+		}
+		config, err := renderNodeConfig(key, attr.value.Elements()[key].(types.String).ValueString(), node, attr.always)
+		if err != nil {
+			diags.AddAttributeError(path.Root(attr.name).AtMapKey(key), common.ErrorLabel, err.Error())
+			return nodeConfig{}, false
+		}
+		return nodeConfig{key: key, config: config}, true
+	}
+
+	if key, ok := configKey(node, sortedKeys(data.NamedConfigs.Elements())); ok {
+		named := cmlschema.GetNamedConfigs(ctx, *diags, data.NamedConfigs.Elements()[key].(types.List))
+		for idx := range named {
+			content, err := renderNodeConfig(key, named[idx].Content, node, false)
+			if err != nil {
+				diags.AddAttributeError(path.Root("named_configs").AtMapKey(key), common.ErrorLabel, err.Error())
+				return nodeConfig{}, false
+			}
+			named[idx].Content = content
+		}
+		return nodeConfig{key: key, named: named}, true
+	}
+	return nodeConfig{}, false
+}
+
+// topologyTarget returns the config target of a topology node, the lab ID
+// is taken from the lifecycle if known.
+func topologyTarget(topo *topology.Topology, node *topology.Node, data *cmlschema.LabLifecycleModel) configTarget {
+	td := common.NewConfigTemplateData(node.Label, node.NodeDefinition, node.Tags)
+	td.Interfaces = node.InterfaceLabels()
+	td.LabTitle = topo.Lab.Title
+	if !data.LabID.IsUnknown() {
+		td.LabID = data.LabID.ValueString()
+	}
+	return configTarget{data: td}
+}
@@ -0,0 +1,125 @@
+package lifecycle
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/rschmied/gocmlclient/pkg/models"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+)
+
+func TestSelectKey(t *testing.T) {
+	keys := []string{"leaf-1", "leaf-*", "l*", "tag:edge", "tag:core", "[bad"}
+
+	tests := []struct {
+		label string
+		tags  []string
+		want  string
+	}{
+		{"leaf-1", []string{"edge"}, "leaf-1"},
+		{"leaf-2", []string{"edge"}, "l*"},
+		{"spine-1", []string{"edge", "core"}, "tag:core"},
+		{"spine-2", nil, ""},
+		{"[bad", nil, "[bad"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			key, ok := selectKey(tt.label, tt.tags, keys)
+			assert.Equal(t, tt.want, key)
+			assert.Equal(t, tt.want != "", ok)
+		})
+	}
+
+	assert.True(t, nodeMatchesKey("leaf-12", nil, "leaf-?2"))
+	assert.False(t, nodeMatchesKey("leaf-1", nil, "tag:leaf-1"))
+	assert.False(t, nodeMatchesKey("x", nil, "[bad"))
+}
+
+func TestResolveNodeConfig(t *testing.T) {
+	ctx := context.Background()
+	data := &cmlschema.LabLifecycleModel{
+		Configs: types.MapValueMust(types.StringType, map[string]attr.Value{
+			"r1":       types.StringValue("hostname {{ .Hostname }}"),
+			"leaf*":    types.StringValue("hostname {{ .Hostname }}\n! {{ join .Tags \" \" }}"),
+			"tag:edge": types.StringValue("hostname {{ .Missing }}"),
+		}),
+		NamedConfigs: types.MapNull(types.ListType{ElemType: cmlschema.NamedConfigAttrType}),
+	}
+
+	target := func(label string, tags ...string) configTarget {
+		return configTarget{data: common.NewConfigTemplateData(label, "iosv", tags)}
+	}
+
+	var diags diag.Diagnostics
+
+	// label keys are not rendered
+	config, ok := resolveNodeConfig(ctx, data, target("r1"), &diags)
+	assert.True(t, ok)
+	assert.Equal(t, "hostname {{ .Hostname }}", config.config)
+
+	config, ok = resolveNodeConfig(ctx, data, target("leaf 1", "edge", "leaf"), &diags)
+	assert.True(t, ok)
+	assert.Equal(t, "leaf*", config.key)
+	assert.Equal(t, "hostname leaf-1\n! edge leaf", config.config)
+	assert.False(t, diags.HasError())
+
+	_, ok = resolveNodeConfig(ctx, data, target("r2"), &diags)
+	assert.False(t, ok)
+	assert.False(t, diags.HasError())
+
+	_, ok = resolveNodeConfig(ctx, data, target("r3", "edge"), &diags)
+	assert.False(t, ok)
+	assert.True(t, diags.HasError())
+}
+
+func TestResolveNodeConfigTemplates(t *testing.T) {
+	ctx := context.Background()
+	data := &cmlschema.LabLifecycleModel{
+		Configs:      types.MapNull(types.StringType),
+		NamedConfigs: types.MapNull(types.ListType{ElemType: cmlschema.NamedConfigAttrType}),
+		ConfigTemplates: types.MapValueMust(types.StringType, map[string]attr.Value{
+			"r1":     types.StringValue("hostname {{ .Hostname }}.{{ .Vars.domain }}\ninterface {{ index .Interfaces 0 }}"),
+			"tag:pc": types.StringValue("{{ .Vars.missing }}"),
+		}),
+		ConfigVars: types.MapValueMust(types.StringType, map[string]attr.Value{
+			"domain": types.StringValue("lab.local"),
+		}),
+	}
+
+	var diags diag.Diagnostics
+	td := common.NewConfigTemplateData("r1", "iosv", nil)
+	td.Interfaces = []string{"Gi0/0", "Gi0/1"}
+
+	// label keys of config_templates are rendered as well
+	config, ok := resolveNodeConfig(ctx, data, configTarget{data: td}, &diags)
+	assert.True(t, ok)
+	assert.Equal(t, "hostname r1.lab.local\ninterface Gi0/0", config.config)
+	assert.False(t, diags.HasError())
+
+	_, ok = resolveNodeConfig(ctx, data, configTarget{data: common.NewConfigTemplateData("pc1", "alpine", []string{"pc"})}, &diags)
+	assert.False(t, ok)
+	assert.True(t, diags.HasError())
+}
+
+func TestCheckUnusedKeys(t *testing.T) {
+	nodes := []*models.Node{
+		{Label: "leaf-1", Tags: []string{"fabric"}},
+		{Label: "spine-1", Tags: []string{"fabric"}},
+	}
+	used := map[string]bool{"leaf-1": true, "tag:fabric": true}
+
+	var diags diag.Diagnostics
+	checkUnusedKeys([]string{"leaf-1", "leaf-*", "tag:fabric"}, used, nodes, &diags)
+	assert.False(t, diags.HasError())
+	assert.Equal(t, 1, diags.WarningsCount())
+
+	diags = nil
+	checkUnusedKeys([]string{"core-*", "tag:infra", "host"}, used, nodes, &diags)
+	assert.Equal(t, 3, diags.ErrorsCount())
+}
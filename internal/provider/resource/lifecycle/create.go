@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
@@ -37,6 +38,7 @@ func (r *LabLifecycleResource) Create(ctx context.Context, req resource.CreateRe
 		staging:  getStaging(ctx, req.Config, &resp.Diagnostics),
 		timeouts: getTimeouts(ctx, req.Config, &resp.Diagnostics),
 		wait:     data.Wait.IsNull() || data.Wait.ValueBool(),
+		backup:   newStagingBackup(),
 	}
 
 	if data.LabID.IsUnknown() {
@@ -65,12 +67,29 @@ func (r *LabLifecycleResource) Create(ctx context.Context, req resource.CreateRe
 	// inject the configurations into the nodes
 	r.injectConfigs(ctx, start.lab, &data, &resp.Diagnostics)
 
+	// place the nodes without coordinates
+	if layoutData := getLayout(ctx, req.Config, &resp.Diagnostics); layoutData != nil {
+		placement := newLayoutPlacement(ctx, resp.Private, data.Topology, &resp.Diagnostics)
+		r.applyLayout(ctx, &resp.Diagnostics, start.lab.ID, layoutData, placement)
+		placement.save(ctx, resp.Private, &resp.Diagnostics)
+	}
+
 	// if unknown state or specifically "start" state, start the lab...
 	// but only if there were no errors from config injection
 	if !resp.Diagnostics.HasError() &&
 		(data.State.IsUnknown() ||
 			data.State.ValueString() == string(models.LabStateStarted)) {
 		r.startNodes(ctx, &resp.Diagnostics, start)
+		setStagingBackup(ctx, resp.Private, start.backup, &resp.Diagnostics)
+		if !resp.Diagnostics.HasError() {
+			wait := getWaitForAddresses(ctx, req.Config, &resp.Diagnostics)
+			r.waitForAddresses(ctx, &resp.Diagnostics, string(start.lab.ID), wait, start.timeouts.Create.ValueString())
+		}
+	}
+
+	if resp.Diagnostics.HasError() {
+		r.createFailed(ctx, resp, &data, start)
+		return
 	}
 
 	// fetch lab again, with nodes and interfaces
@@ -80,14 +99,91 @@ func (r *LabLifecycleResource) Create(ctx context.Context, req resource.CreateRe
 			common.ErrorLabel,
 			fmt.Sprintf("Unable to get lab, got error: %s", err),
 		)
+		r.createFailed(ctx, resp, &data, start)
 		return
 	}
 
+	r.applyAutoStop(ctx, &resp.Diagnostics, &lab, &data)
+	r.setCreatedState(ctx, resp, &data, &lab)
+	tflog.Info(ctx, "Resource Lifecycle CREATE done")
+}
+
+// setCreatedState records the lab in the resource state. If the diagnostics
+// contain errors at this point then Terraform marks the resource as tainted.
+func (r *LabLifecycleResource) setCreatedState(ctx context.Context, resp *resource.CreateResponse, data *cmlschema.LabLifecycleModel, lab *models.Lab) {
 	data.LabID = types.StringValue(string(lab.ID))
 	data.State = types.StringValue(string(lab.State))
-	data.Nodes = r.populateNodes(ctx, &lab, &resp.Diagnostics)
+	data.Nodes = r.populateNodes(ctx, lab, &resp.Diagnostics)
+	populateAddresses(ctx, lab, data, &resp.Diagnostics)
 	data.Booted = types.BoolValue(lab.Booted())
+	// no deadline is recorded when the lab did not start
+	if data.AutoStopDeadline.IsUnknown() {
+		data.AutoStopDeadline = types.StringNull()
+	}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
-	tflog.Info(ctx, "Resource Lifecycle CREATE done")
+	resp.Diagnostics.Append(resp.State.Set(ctx, data)...)
+}
+
+// createFailed applies the on_failure policy after the lab has been imported
+// (or referenced) but configuration injection or starting the nodes failed.
+// Unless the lab is destroyed, it is recorded in state so that it does not
+// leak.
+func (r *LabLifecycleResource) createFailed(ctx context.Context, resp *resource.CreateResponse, data *cmlschema.LabLifecycleModel, start startData) {
+	policy := data.OnFailure.ValueString()
+	if policy == "" {
+		policy = cmlschema.OnFailureKeep
+	}
+	owned := !data.Topology.IsNull()
+	tflog.Warn(ctx, "lifecycle create failed", map[string]any{"policy": policy, "lab_id": start.lab.ID, "owned": owned})
+
+	// errors from the cleanup are collected separately, the original errors
+	// are already in the response
+	var cleanup diag.Diagnostics
+
+	lab, err := r.cfg.Client().Lab.GetByID(ctx, start.lab.ID, true)
+	if err != nil {
+		cleanup.AddError(
+			common.ErrorLabel,
+			fmt.Sprintf("Unable to get lab, got error: %s", err),
+		)
+		lab = *start.lab
+	}
+
+	switch {
+	case policy == cmlschema.OnFailureDestroy && owned && !cleanup.HasError():
+		td := newTeardownData(&lab, start.staging, start.timeouts.Delete.ValueString(), &cleanup)
+		if !cleanup.HasError() {
+			r.teardown(ctx, &cleanup, td)
+		}
+		if !cleanup.HasError() {
+			err = r.cfg.Client().Lab.Delete(ctx, lab.ID)
+			if err == nil {
+				tflog.Info(ctx, "lab destroyed after failed create")
+				return
+			}
+			cleanup.AddError(
+				common.ErrorLabel,
+				fmt.Sprintf("Unable to destroy CML2 lab, got error: %s", err),
+			)
+		}
+	case policy != cmlschema.OnFailureKeep && !cleanup.HasError():
+		if lab.State == models.LabStateStarted || lab.Running() {
+			r.stop(ctx, &cleanup, string(lab.ID))
+			if !cleanup.HasError() {
+				common.Converge(ctx, r.cfg.Client(), &cleanup, string(lab.ID), start.timeouts.Delete.ValueString())
+			}
+			if refreshed, refreshErr := r.cfg.Client().Lab.GetByID(ctx, lab.ID, true); refreshErr == nil {
+				lab = refreshed
+			}
+		}
+	}
+
+	// the cleanup failed or the lab is kept, record it (as tainted)
+	for _, d := range cleanup {
+		resp.Diagnostics.AddWarning(
+			"Cleanup after failed create",
+			fmt.Sprintf("%s: %s", d.Summary(), d.Detail()),
+		)
+	}
+	r.setCreatedState(ctx, resp, data, &lab)
 }
@@ -12,7 +12,7 @@ import (
 	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
 )
 
-// Delete stops/wipes and deletes the managed lab.
+// Delete gracefully tears down (stops/wipes) and deletes the managed lab.
 func (r *LabLifecycleResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	var data cmlschema.LabLifecycleModel
 
@@ -25,10 +25,11 @@ func (r *LabLifecycleResource) Delete(ctx context.Context, req resource.DeleteRe
 
 	if data.Topology.IsNull() {
 		tflog.Warn(ctx, "won't destroy as there's no topology")
+		r.deleteRestoreStaging(ctx, req, resp, &data)
 		return
 	}
 
-	lab, err := r.cfg.Client().Lab.GetByID(ctx, models.UUID(data.LabID.ValueString()), false)
+	lab, err := r.cfg.Client().Lab.GetByID(ctx, models.UUID(data.LabID.ValueString()), true)
 	if err != nil {
 		if common.IsNotFound(err) {
 			// Lab already deleted externally.
@@ -42,11 +43,16 @@ func (r *LabLifecycleResource) Delete(ctx context.Context, req resource.DeleteRe
 		return
 	}
 
-	if lab.State != models.LabStateDefined {
-		if lab.State == models.LabStateStarted {
-			r.stop(ctx, resp.Diagnostics, data.LabID.ValueString())
-		}
-		r.wipe(ctx, resp.Diagnostics, data.LabID.ValueString())
+	timeouts := getTimeouts(ctx, req.State, &resp.Diagnostics)
+	staging := getStaging(ctx, req.State, &resp.Diagnostics)
+	td := newTeardownData(&lab, staging, timeouts.Delete.ValueString(), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.teardown(ctx, &resp.Diagnostics, td)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
 	err = r.cfg.Client().Lab.Delete(ctx, models.UUID(data.LabID.ValueString()))
@@ -59,3 +65,24 @@ func (r *LabLifecycleResource) Delete(ctx context.Context, req resource.DeleteRe
 	}
 	tflog.Info(ctx, "Resource Lifecycle DELETE done")
 }
+
+// deleteRestoreStaging restores what server-side staging has changed in a lab
+// which is kept on destroy.
+func (r *LabLifecycleResource) deleteRestoreStaging(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse, data *cmlschema.LabLifecycleModel) {
+	backup := getStagingBackup(ctx, req.Private, &resp.Diagnostics)
+	if !backup.Recorded {
+		return
+	}
+	lab, err := r.cfg.Client().Lab.GetByID(ctx, models.UUID(data.LabID.ValueString()), true)
+	if err != nil {
+		if common.IsNotFound(err) {
+			return
+		}
+		resp.Diagnostics.AddError(
+			common.ErrorLabel,
+			fmt.Sprintf("Unable to read CML2 lab, got error: %s", err),
+		)
+		return
+	}
+	r.restoreStaging(ctx, &resp.Diagnostics, &lab, backup)
+}
@@ -2,12 +2,67 @@ package lifecycle
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/rschmied/gocmlclient/pkg/models"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+)
+
+const (
+	importTitlePrefix = "title:"
+	importLabIDPrefix = "lab_id:"
 )
 
-// ImportState imports the lifecycle resource state.
+// parseImportID splits the import ID into the lab title or the lab ID. A
+// plain ID without prefix is taken as the lab ID.
+func parseImportID(id string) (title, labID string, err error) {
+	if value, ok := strings.CutPrefix(id, importTitlePrefix); ok {
+		if value == "" {
+			return "", "", fmt.Errorf("empty lab title in import ID %q", id)
+		}
+		return value, "", nil
+	}
+	value, _ := strings.CutPrefix(id, importLabIDPrefix)
+	if value == "" {
+		return "", "", fmt.Errorf("empty lab ID in import ID %q", id)
+	}
+	return "", value, nil
+}
+
+// ImportState imports the lifecycle resource state. The import ID is either
+// `title:<lab title>`, `lab_id:<lab ID>` or the lab ID. The lab is looked up
+// and both, the resource ID and the lab ID, are set to the lab ID. Read then
+// reconstructs nodes, state and booted from the live lab.
 func (r LabLifecycleResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	title, labID, err := parseImportID(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid import ID",
+			fmt.Sprintf("Expected \"title:<lab title>\", \"lab_id:<lab ID>\" or a lab ID: %s", err),
+		)
+		return
+	}
+
+	var lab models.Lab
+	if title != "" {
+		lab, err = r.cfg.Client().Lab.GetByTitle(ctx, title, false)
+	} else {
+		lab, err = r.cfg.Client().Lab.GetByID(ctx, models.UUID(labID), false)
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			common.ErrorLabel,
+			fmt.Sprintf("Unable to find lab to import (%s), got error: %s", req.ID, err),
+		)
+		return
+	}
+	tflog.Info(ctx, "lifecycle import", map[string]any{"import_id": req.ID, "lab_id": lab.ID})
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), string(lab.ID))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("lab_id"), string(lab.ID))...)
 }
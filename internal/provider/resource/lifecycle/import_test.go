@@ -0,0 +1,36 @@
+package lifecycle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseImportID(t *testing.T) {
+	tests := []struct {
+		id      string
+		title   string
+		labID   string
+		wantErr bool
+	}{
+		{id: "title:my lab", title: "my lab"},
+		{id: "title:lab_id:odd", title: "lab_id:odd"},
+		{id: "lab_id:4a3b8c2e-2f3c-4b1e-9d7f-0c9a1e2b3c4d", labID: "4a3b8c2e-2f3c-4b1e-9d7f-0c9a1e2b3c4d"},
+		{id: "4a3b8c2e-2f3c-4b1e-9d7f-0c9a1e2b3c4d", labID: "4a3b8c2e-2f3c-4b1e-9d7f-0c9a1e2b3c4d"},
+		{id: "title:", wantErr: true},
+		{id: "lab_id:", wantErr: true},
+		{id: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			title, labID, err := parseImportID(tt.id)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.title, title)
+			assert.Equal(t, tt.labID, labID)
+		})
+	}
+}
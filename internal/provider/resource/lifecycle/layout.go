@@ -0,0 +1,195 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/rschmied/gocmlclient/pkg/models"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/layout"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/topology"
+)
+
+// defaultLayoutSpacing is the distance between nodes if the layout has no
+// spacing configured.
+const defaultLayoutSpacing = 120
+
+func getLayout(ctx context.Context, config attributeGetter, diags *diag.Diagnostics) *cmlschema.LayoutModel {
+	var result *cmlschema.LayoutModel
+	diags.Append(config.GetAttribute(ctx, path.Root("layout"), &result)...)
+	return result
+}
+
+// layoutPlacedKey is the private state key holding the IDs of the nodes
+// which have been placed by the layout.
+const layoutPlacedKey = "layout_placed"
+
+// privateData reads and writes resource private state.
+type privateData interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// layoutPlacement decides which nodes the layout may move. Nodes with
+// coordinates in the topology are managed by the topology. Other nodes are
+// only moved when they are at the origin (where nodes created without
+// coordinates are) and haven't been placed before: a placed node which is
+// back at the origin has been moved there by its configuration.
+type layoutPlacement struct {
+	placed     map[string]bool // node IDs
+	configured map[string]bool // node labels
+}
+
+// newLayoutPlacement returns the placement from the topology and the nodes
+// which have been placed by earlier applies.
+func newLayoutPlacement(ctx context.Context, private privateData, topologyText types.String, diags *diag.Diagnostics) layoutPlacement {
+	placement := layoutPlacement{placed: map[string]bool{}, configured: map[string]bool{}}
+
+	data, d := private.GetKey(ctx, layoutPlacedKey)
+	diags.Append(d...)
+	if len(data) > 0 {
+		ids := []string{}
+		if err := json.Unmarshal(data, &ids); err != nil {
+			tflog.Warn(ctx, "layout: ignoring invalid private state", map[string]any{"error": err.Error()})
+		}
+		for _, id := range ids {
+			placement.placed[id] = true
+		}
+	}
+
+	if topologyText.IsNull() || topologyText.IsUnknown() {
+		return placement
+	}
+	// the topology is validated in ModifyPlan
+	topo, err := topology.Parse(topologyText.ValueString())
+	if err != nil {
+		return placement
+	}
+	for _, node := range topo.Nodes {
+		if node.HasPosition() {
+			placement.configured[node.Label] = true
+		}
+	}
+	return placement
+}
+
+// movable reports whether the layout may move the node.
+func (p layoutPlacement) movable(id, label string, x, y int) bool {
+	return layout.Unplaced(x, y) && !p.placed[id] && !p.configured[label]
+}
+
+// save stores the placed nodes in the private state.
+func (p layoutPlacement) save(ctx context.Context, private privateData, diags *diag.Diagnostics) {
+	ids := make([]string, 0, len(p.placed))
+	for id := range p.placed {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	data, err := json.Marshal(ids)
+	if err != nil {
+		diags.AddError(common.ErrorLabel, fmt.Sprintf("Unable to store placed nodes, got error: %s", err))
+		return
+	}
+	diags.Append(private.SetKey(ctx, layoutPlacedKey, data)...)
+}
+
+// layoutGraph returns the link graph of the lab, nodes which the layout
+// can't move are fixed.
+func layoutGraph(lab *models.Lab, placement layoutPlacement) layout.Graph {
+	graph := layout.Graph{}
+	for _, node := range lab.Nodes {
+		if node == nil {
+			continue
+		}
+		graph.Nodes = append(graph.Nodes, layout.Node{
+			ID:    string(node.ID),
+			Label: node.Label,
+			Tags:  node.Tags,
+			Fixed: !placement.movable(string(node.ID), node.Label, node.X, node.Y),
+			X:     node.X,
+			Y:     node.Y,
+		})
+	}
+	for _, link := range lab.Links {
+		graph.Edges = append(graph.Edges, [2]string{string(link.SrcNode), string(link.DstNode)})
+	}
+	return graph
+}
+
+// hasUnplacedNodes reports whether the layout has nodes to move.
+func hasUnplacedNodes(nodes map[string]cmlschema.NodeModel, placement layoutPlacement) bool {
+	for id, node := range nodes {
+		if node.X.IsNull() || node.X.IsUnknown() || node.Y.IsNull() || node.Y.IsUnknown() {
+			continue
+		}
+		if placement.movable(id, node.Label.ValueString(), int(node.X.ValueInt64()), int(node.Y.ValueInt64())) {
+			return true
+		}
+	}
+	return false
+}
+
+// computeLayout returns the positions of the nodes of the graph which are
+// not fixed.
+func computeLayout(graph layout.Graph, data *cmlschema.LayoutModel) map[string]layout.Point {
+	spacing := defaultLayoutSpacing
+	if !data.Spacing.IsNull() && !data.Spacing.IsUnknown() {
+		spacing = int(data.Spacing.ValueInt64())
+	}
+	switch data.Mode.ValueString() {
+	case cmlschema.LayoutModeHierarchical:
+		tiers := []string{}
+		for _, elem := range data.Tiers.Elements() {
+			tiers = append(tiers, elem.(types.String).ValueString())
+		}
+		return layout.Hierarchical(graph, tiers, spacing)
+	case cmlschema.LayoutModeForce:
+		return layout.Force(graph, spacing)
+	}
+	return layout.Grid(graph, spacing)
+}
+
+// applyLayout moves the nodes of the lab which have no coordinates to the
+// positions computed by the configured layout, the moved nodes are added to
+// the placement.
+func (r *LabLifecycleResource) applyLayout(ctx context.Context, diags *diag.Diagnostics, labID models.UUID, data *cmlschema.LayoutModel, placement layoutPlacement) {
+	if data == nil || diags.HasError() {
+		return
+	}
+
+	lab, err := r.cfg.Client().Lab.GetByID(ctx, labID, true)
+	if err != nil {
+		diags.AddError(
+			common.ErrorLabel,
+			fmt.Sprintf("Unable to get lab for layout, got error: %s", err),
+		)
+		return
+	}
+
+	positions := computeLayout(layoutGraph(&lab, placement), data)
+	tflog.Info(ctx, "layout: placing nodes", map[string]any{"mode": data.Mode.ValueString(), "nodes": len(positions)})
+	for id, point := range positions {
+		current, ok := lab.Nodes[models.UUID(id)]
+		if !ok {
+			continue
+		}
+		node := *current
+		node.X, node.Y = point.X, point.Y
+		if _, err := r.cfg.Client().Node.Update(ctx, node); err != nil {
+			diags.AddError(
+				common.ErrorLabel,
+				fmt.Sprintf("Unable to set position of node %s, got error: %s", node.Label, err),
+			)
+			return
+		}
+		placement.placed[id] = true
+	}
+}
@@ -0,0 +1,111 @@
+package lifecycle
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/rschmied/gocmlclient/pkg/models"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+)
+
+func TestComputeLayout(t *testing.T) {
+	lab := &models.Lab{
+		Nodes: models.NodeMap{
+			"n1": {ID: "n1", Label: "core", X: 100, Y: 100, Tags: []string{"core"}},
+			"n2": {ID: "n2", Label: "leaf-1", Tags: []string{"edge"}},
+			"n3": {ID: "n3", Label: "leaf-2", Tags: []string{"edge"}},
+			"n4": nil,
+		},
+		Links: models.LinkList{
+			{SrcNode: "n1", DstNode: "n2"},
+			{SrcNode: "n1", DstNode: "n3"},
+		},
+	}
+
+	graph := layoutGraph(lab, layoutPlacement{})
+	assert.Len(t, graph.Nodes, 3)
+	assert.Len(t, graph.Edges, 2)
+
+	positions := computeLayout(graph, &cmlschema.LayoutModel{
+		Mode:    types.StringValue(cmlschema.LayoutModeHierarchical),
+		Spacing: types.Int64Value(50),
+		Tiers:   types.ListValueMust(types.StringType, []attr.Value{types.StringValue("edge")}),
+	})
+	assert.Len(t, positions, 2)
+	assert.NotContains(t, positions, "n1")
+	for _, p := range positions {
+		assert.Equal(t, 150, p.Y)
+	}
+
+	positions = computeLayout(graph, &cmlschema.LayoutModel{
+		Mode:    types.StringValue(cmlschema.LayoutModeGrid),
+		Spacing: types.Int64Null(),
+		Tiers:   types.ListNull(types.StringType),
+	})
+	assert.Equal(t, 100+defaultLayoutSpacing, positions["n2"].Y)
+}
+
+func TestHasUnplacedNodes(t *testing.T) {
+	node := func(label string, x, y types.Int64) cmlschema.NodeModel {
+		return cmlschema.NodeModel{Label: types.StringValue(label), X: x, Y: y}
+	}
+	assert.False(t, hasUnplacedNodes(map[string]cmlschema.NodeModel{
+		"n1": node("r1", types.Int64Value(0), types.Int64Value(10)),
+		"n2": node("r2", types.Int64Unknown(), types.Int64Unknown()),
+	}, layoutPlacement{}))
+	assert.True(t, hasUnplacedNodes(map[string]cmlschema.NodeModel{
+		"n1": node("r1", types.Int64Value(0), types.Int64Value(0)),
+	}, layoutPlacement{}))
+	// placed before, moved back to the origin by its configuration
+	assert.False(t, hasUnplacedNodes(map[string]cmlschema.NodeModel{
+		"n1": node("r1", types.Int64Value(0), types.Int64Value(0)),
+	}, layoutPlacement{placed: map[string]bool{"n1": true}}))
+	// coordinates in the topology
+	assert.False(t, hasUnplacedNodes(map[string]cmlschema.NodeModel{
+		"n1": node("r1", types.Int64Value(0), types.Int64Value(0)),
+	}, layoutPlacement{configured: map[string]bool{"r1": true}}))
+}
+
+// fakePrivate is an in-memory resource private state.
+type fakePrivate map[string][]byte
+
+func (f fakePrivate) GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics) {
+	return f[key], nil
+}
+
+func (f fakePrivate) SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics {
+	f[key] = value
+	return nil
+}
+
+func TestLayoutPlacement(t *testing.T) {
+	ctx := context.Background()
+	var diags diag.Diagnostics
+	private := fakePrivate{}
+
+	topo := types.StringValue(`
+nodes:
+  - label: r1
+    x: 0
+    y: 0
+  - label: r2
+`)
+	placement := newLayoutPlacement(ctx, private, topo, &diags)
+	assert.False(t, placement.movable("n1", "r1", 0, 0))
+	assert.True(t, placement.movable("n2", "r2", 0, 0))
+	assert.False(t, placement.movable("n2", "r2", 10, 0))
+
+	placement.placed["n2"] = true
+	placement.save(ctx, private, &diags)
+	assert.False(t, diags.HasError())
+	assert.JSONEq(t, `["n2"]`, string(private[layoutPlacedKey]))
+
+	placement = newLayoutPlacement(ctx, private, types.StringNull(), &diags)
+	assert.False(t, placement.movable("n2", "r2", 0, 0))
+	assert.True(t, placement.movable("n3", "r3", 0, 0))
+}
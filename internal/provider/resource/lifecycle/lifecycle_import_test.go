@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
 
 	cfg "github.com/ciscodevnet/terraform-provider-cml2/internal/testing"
 )
@@ -31,6 +32,50 @@ func TestAccLifecycleImport(t *testing.T) {
 	})
 }
 
+func TestAccLifecycleImportByTitle(t *testing.T) {
+	cfg.SkipUnlessAcc(t)
+
+	const title = "acc lifecycle import by title"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLifecycleImportByTitle(cfg.Cfg, title),
+				Check:  resource.TestCheckResourceAttrWith("cml2_lifecycle.top", "lab_id", uuidCheck),
+			},
+			// the lifecycle ID is random, the imported one is the lab ID
+			{
+				ResourceName:            "cml2_lifecycle.top",
+				ImportState:             true,
+				ImportStateId:           "title:" + title,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"id"},
+			},
+			{
+				ResourceName: "cml2_lifecycle.top",
+				ImportState:  true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					return "lab_id:" + s.RootModule().Resources["cml2_lab.this"].Primary.ID, nil
+				},
+				ImportStatePersist: true,
+			},
+			// the plan right after the import is clean
+			{
+				Config:   testAccLifecycleImportByTitle(cfg.Cfg, title),
+				PlanOnly: true,
+			},
+			{
+				ResourceName:  "cml2_lifecycle.top",
+				ImportState:   true,
+				ImportStateId: "title:no such lab",
+				ExpectError:   regexp.MustCompile(`Unable to find lab to import`),
+			},
+		},
+	})
+}
+
 func TestAccLifecycleConfigCheck(t *testing.T) {
 	cfg.SkipUnlessAcc(t)
 
@@ -99,6 +144,40 @@ func TestAccLifecycleImportLab(t *testing.T) {
 	})
 }
 
+func TestAccLifecycleOnFailure(t *testing.T) {
+	cfg.SkipUnlessAcc(t)
+
+	// node IDs are not validated at plan time, a configuration for a node ID
+	// which doesn't exist makes the create fail after the lab was imported
+	missing := "00000000-0000-4000-8000-000000000000"
+	re := regexp.MustCompile(`node with label ` + missing + ` not found`)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// the imported lab is destroyed again, nothing in state
+			{
+				Config:      testAccLifecycleOnFailure(cfg.Cfg, "destroy", missing),
+				ExpectError: re,
+			},
+			// the imported lab is kept and recorded as tainted
+			{
+				Config:      testAccLifecycleOnFailure(cfg.Cfg, "keep", missing),
+				ExpectError: re,
+			},
+			// the tainted lab is replaced
+			{
+				Config: testAccLifecycleOnFailure(cfg.Cfg, "keep", "nginx-0"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrWith("cml2_lifecycle.top", "lab_id", uuidCheck),
+					resource.TestCheckResourceAttr("cml2_lifecycle.top", "state", "DEFINED_ON_CORE"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccLifecycleResourceState(t *testing.T) {
 	cfg.SkipUnlessAcc(t)
 
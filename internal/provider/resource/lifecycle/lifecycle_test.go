@@ -316,6 +316,35 @@ output "n0config" {
 `, cfg, label, nodeCfg)
 }
 
+func testAccLifecycleOnFailure(cfg, policy, label string) string {
+	// BEWARE!! the yaml below must be indented with spaces, not with tabs!!
+	return fmt.Sprintf(`
+%[1]s
+resource "cml2_lifecycle" "top" {
+	topology = <<EOT
+    lab:
+        description: ''
+        notes: ''
+        title: acc lifecycle on failure
+        version: 0.1.0
+    links: []
+    nodes:
+        - id: n0
+          label: nginx-0
+          x: 1
+          y: 1
+          node_definition: nginx
+          interfaces: []
+EOT
+	configs = {
+		"%[3]s": "hostname nginx-0",
+	}
+	state      = "DEFINED_ON_CORE"
+	on_failure = %[2]q
+}
+`, cfg, policy, label)
+}
+
 func testAccLifecycleImport(cfg string) string {
 	return fmt.Sprintf(`
 %[1]s
@@ -325,6 +354,27 @@ resource "cml2_lab" "this" {
 `, cfg)
 }
 
+func testAccLifecycleImportByTitle(cfg, title string) string {
+	return fmt.Sprintf(`
+%[1]s
+resource "cml2_lab" "this" {
+	title = %[2]q
+}
+
+resource "cml2_node" "r1" {
+	lab_id         = cml2_lab.this.id
+	label          = "R1"
+	nodedefinition = "nginx"
+}
+
+resource "cml2_lifecycle" "top" {
+	lab_id     = cml2_lab.this.id
+	state      = "DEFINED_ON_CORE"
+	depends_on = [cml2_node.r1]
+}
+`, cfg, title)
+}
+
 func testAccLifecycleAddNodeToBooted(cfg, title string, stage int) string {
 	if stage == 0 {
 		return fmt.Sprintf(`
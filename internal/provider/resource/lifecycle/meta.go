@@ -28,8 +28,10 @@ type LabLifecycleResource struct {
 }
 
 type labLifecycleStaging struct {
-	Stages         types.List `tfsdk:"stages"`
-	StartRemaining types.Bool `tfsdk:"start_remaining"`
+	Stages         types.List   `tfsdk:"stages"`
+	StartRemaining types.Bool   `tfsdk:"start_remaining"`
+	AbortOnFailure types.Bool   `tfsdk:"abort_on_failure"`
+	Mode           types.String `tfsdk:"mode"`
 }
 
 type labLifecycleTimeouts struct {
@@ -38,11 +40,18 @@ type labLifecycleTimeouts struct {
 	Delete types.String `tfsdk:"delete"`
 }
 
+type labLifecycleWaitForAddresses struct {
+	Interfaces    types.Map    `tfsdk:"interfaces"`
+	AddressFamily types.String `tfsdk:"address_family"`
+	Timeout       types.String `tfsdk:"timeout"`
+}
+
 type startData struct {
 	wait     bool
 	lab      *models.Lab
 	staging  *labLifecycleStaging
 	timeouts *labLifecycleTimeouts
+	backup   *stagingBackup
 }
 
 // Schema returns the schema for the lifecycle resource.
@@ -81,6 +90,15 @@ func (r *LabLifecycleResource) ValidateConfig(ctx context.Context, req resource.
 		return
 	}
 
+	if len(data.ConfigTemplates.Elements()) > 0 && (len(data.Configs.Elements()) > 0 || len(data.NamedConfigs.Elements()) > 0) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("config_templates"),
+			"Conflicting configuration",
+			"Can't set \"config_templates\" together with \"configs\" or \"named_configs\".",
+		)
+		return
+	}
+
 	// deprecated, June 2024, can't enforce this:
 	//
 	// id and elements are mutually exclusive with topology
@@ -93,6 +111,11 @@ func (r *LabLifecycleResource) ValidateConfig(ctx context.Context, req resource.
 	// 	return
 	// }
 
+	validateTopology(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	if len(data.Elements.Elements()) > 0 {
 		resp.Diagnostics.AddAttributeWarning(
 			path.Root("elements"),
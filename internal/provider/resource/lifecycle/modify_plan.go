@@ -2,7 +2,11 @@ package lifecycle
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -62,14 +66,28 @@ func (r *LabLifecycleResource) ModifyPlan(ctx context.Context, req resource.Modi
 		}
 	}
 
+	// check the topology against the controller's definitions when it is
+	// new or changed
+	if noState || !configData.Topology.Equal(stateData.Topology) {
+		r.validateTopologyDefinitions(ctx, &configData, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	// store the default in state if it is not provided in the configuration
 	if configData.State.IsNull() {
 		planData.State = types.StringValue("STARTED")
 	}
 
+	// past the auto stop deadline, the lab is planned to be stopped
+	planAutoStop(&resp.Diagnostics, &configData, &stateData, &planData, noState, time.Now())
+
 	changeNeeded := false
 	stateTransition := false
 	triggerChanged := false
+	topologyUpdate := false
+	layoutUpdate := false
 	var nodes map[string]cmlschema.NodeModel
 	if !noState {
 		// Fetch prior node state once; used for both drift detection and plan
@@ -115,6 +133,65 @@ func (r *LabLifecycleResource) ModifyPlan(ctx context.Context, req resource.Modi
 			changeNeeded = true
 		}
 
+		// Restart triggers restart the selected nodes in Update, which only
+		// happens while the lab stays STARTED.
+		if !changeNeeded && planData.State.ValueString() == string(models.LabStateStarted) &&
+			len(changedRestartKeys(stateData.RestartTriggers, configData.RestartTriggers)) > 0 {
+			triggerChanged = true
+			changeNeeded = true
+		}
+
+		// Topology changes are applied in place when possible, otherwise the
+		// lab is replaced. Without a topology (lab_id given), configuration
+		// changes always require a replace.
+		if topologyChanged(&stateData, &configData) {
+			switch {
+			case configData.Topology.IsNull() || stateData.Topology.IsNull():
+				resp.RequiresReplace = append(resp.RequiresReplace, path.Root("topology"), path.Root("configs"), path.Root("named_configs"), path.Root("config_templates"), path.Root("config_vars"))
+				// e.g. an imported lifecycle, its lab is not owned and is
+				// neither changed nor deleted by the replace
+				if stateData.Topology.IsNull() && !configData.Topology.IsNull() {
+					resp.Diagnostics.AddAttributeWarning(
+						path.Root("topology"),
+						"Lifecycle without topology is replaced",
+						fmt.Sprintf(
+							"The lifecycle refers to lab %s via lab_id, its topology is not known. A new lab is created from the configured topology, lab %s is left as is. Use lab_id to manage an imported lab.",
+							stateData.LabID.ValueString(), stateData.LabID.ValueString(),
+						),
+					)
+				}
+			case configData.Topology.IsUnknown() || configData.Configs.IsUnknown() || configData.NamedConfigs.IsUnknown() ||
+				configData.ConfigTemplates.IsUnknown() || configData.ConfigVars.IsUnknown():
+				resp.RequiresReplace = append(resp.RequiresReplace, path.Root("topology"))
+			default:
+				diff := planTopologyDiff(ctx, &stateData, &configData, &resp.Diagnostics)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+				if !diff.Supported() {
+					resp.RequiresReplace = append(resp.RequiresReplace, path.Root("topology"))
+					resp.Diagnostics.AddAttributeWarning(
+						path.Root("topology"),
+						"Topology change requires replacement",
+						fmt.Sprintf("The topology changes can't be applied in place: %s.", strings.Join(diff.Unsupported, ", ")),
+					)
+				} else if !diff.Empty() {
+					topologyUpdate = true
+					changeNeeded = true
+				}
+			}
+		}
+
+		// The layout moves nodes without coordinates in Update, plan an
+		// update when it changed or when there are nodes to place.
+		if !configData.Layout.IsNull() {
+			placement := newLayoutPlacement(ctx, req.Private, configData.Topology, &resp.Diagnostics)
+			if !configData.Layout.Equal(stateData.Layout) || hasUnplacedNodes(nodes, placement) {
+				layoutUpdate = true
+				changeNeeded = true
+			}
+		}
+
 		// Determine staging behavior from config once.
 		staging := getStaging(ctx, req.Config, &resp.Diagnostics)
 		if resp.Diagnostics.HasError() {
@@ -231,9 +308,18 @@ func (r *LabLifecycleResource) ModifyPlan(ctx context.Context, req resource.Modi
 				}
 			}
 		}
+		// moving to DEFINED_ON_CORE wipes the nodes, correcting drift as well
+		if changeNeeded && planData.State.ValueString() == string(models.LabStateDefined) {
+			checkWipe(&resp.Diagnostics, nodes, &configData)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+		}
+
 		tflog.Info(ctx, "Lifecycle MP decision", map[string]any{
 			"state_transition": stateTransition,
 			"trigger_changed":  triggerChanged,
+			"layout_update":    layoutUpdate,
 			"change_needed":    changeNeeded,
 			"desired_state":    planData.State.ValueString(),
 		})
@@ -245,7 +331,7 @@ func (r *LabLifecycleResource) ModifyPlan(ctx context.Context, req resource.Modi
 		// For synthetic trigger-only updates, do not rewrite nested computed
 		// node data from prior state. Doing so can conflict with concurrent
 		// node add/replace operations in the same apply.
-		if triggerChanged && !stateTransition {
+		if triggerChanged && !stateTransition && !topologyUpdate && !layoutUpdate {
 			resp.Diagnostics.Append(resp.Plan.Set(ctx, &planData)...)
 			tflog.Info(ctx, "Resource Lifecycle MODIFYPLAN done")
 			return
@@ -433,6 +519,15 @@ func (r *LabLifecycleResource) ModifyPlan(ctx context.Context, req resource.Modi
 			return
 		}
 
+		// nodes are added, removed or replaced by topology changes
+		if topologyUpdate {
+			planData.Nodes = types.MapUnknown(types.ObjectType{AttrTypes: cmlschema.NodeAttrType})
+		}
+
+		// addresses are assigned (or go away) with the node state changes
+		planData.Addresses = types.MapUnknown(types.MapType{ElemType: types.ListType{ElemType: types.StringType}})
+		planData.ManagementIP = types.MapUnknown(types.StringType)
+
 		// booted state of lab is unknown if the plan is to start
 		if planData.State.ValueString() == string(models.LabStateStarted) {
 			planData.Booted = types.BoolUnknown()
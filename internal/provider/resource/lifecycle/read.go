@@ -42,7 +42,9 @@ func (r *LabLifecycleResource) Read(ctx context.Context, req resource.ReadReques
 	data.LabID = types.StringValue(string(lab.ID))
 	data.State = types.StringValue(string(lab.State))
 	data.Nodes = r.populateNodes(ctx, &lab, &resp.Diagnostics)
+	populateAddresses(ctx, &lab, &data, &resp.Diagnostics)
 	data.Booted = types.BoolValue(lab.Booted())
+	readAutoStop(&lab, &data)
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, data)...)
 	if resp.Diagnostics.HasError() {
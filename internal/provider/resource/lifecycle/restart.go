@@ -0,0 +1,124 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/rschmied/gocmlclient/pkg/models"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+)
+
+// changedRestartKeys returns the restart trigger keys which are new or have a
+// different value than before, in sort order. Removed keys don't trigger a
+// restart.
+func changedRestartKeys(old, current types.Map) []string {
+	if current.IsNull() || current.IsUnknown() {
+		return nil
+	}
+	previous := map[string]string{}
+	if !old.IsNull() && !old.IsUnknown() {
+		for key, value := range old.Elements() {
+			previous[key] = value.(types.String).ValueString()
+		}
+	}
+	keys := []string{}
+	for key, value := range current.Elements() {
+		if prev, ok := previous[key]; !ok || prev != value.(types.String).ValueString() {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// restartBatches returns the running nodes selected by any of the keys in
+// batches, in staging order.
+func restartBatches(lab *models.Lab, keys []string, stages []string) [][]*models.Node {
+	selected := &models.Lab{Nodes: models.NodeMap{}}
+	for id, node := range lab.Nodes {
+		if node == nil || !nodeIsRunning(node) {
+			continue
+		}
+		if slices.ContainsFunc(keys, func(key string) bool {
+			return nodeMatchesKey(node.Label, node.Tags, key)
+		}) {
+			selected.Nodes[id] = node
+		}
+	}
+	batches := shutdownOrder(selected, stages)
+	slices.Reverse(batches)
+	return batches
+}
+
+// restartNodes restarts the running nodes selected by the changed restart
+// trigger keys, one batch at a time: the nodes of a batch are stopped,
+// optionally wiped and started again, the next batch is only restarted after
+// the lab has converged.
+func (r *LabLifecycleResource) restartNodes(ctx context.Context, diags *diag.Diagnostics, start startData, keys []string, wipe bool) {
+	if len(keys) == 0 {
+		return
+	}
+	client := r.cfg.Client()
+	timeout := start.timeouts.Update.ValueString()
+
+	stages := []string{}
+	if start.staging != nil {
+		for _, stageElem := range start.staging.Stages.Elements() {
+			stages = append(stages, stageElem.(types.String).ValueString())
+		}
+	}
+	batches := restartBatches(start.lab, keys, stages)
+	tflog.Info(ctx, "restarting nodes", map[string]any{"keys": keys, "batches": len(batches), "wipe": wipe})
+
+	for _, batch := range batches {
+		for _, node := range batch {
+			tflog.Info(ctx, fmt.Sprintf("stopping node %s", node.Label))
+			if err := client.Node.Stop(ctx, start.lab.ID, node.ID); err != nil {
+				diags.AddError(
+					common.ErrorLabel,
+					fmt.Sprintf("Unable to stop node %s, got error: %s", node.Label, err),
+				)
+				return
+			}
+		}
+		common.Converge(ctx, client, diags, string(start.lab.ID), timeout)
+		if diags.HasError() {
+			return
+		}
+
+		if wipe {
+			for _, node := range batch {
+				tflog.Info(ctx, fmt.Sprintf("wiping node %s", node.Label))
+				if err := client.Node.Wipe(ctx, start.lab.ID, node.ID); err != nil {
+					diags.AddError(
+						common.ErrorLabel,
+						fmt.Sprintf("Unable to wipe node %s, got error: %s", node.Label, err),
+					)
+					return
+				}
+			}
+		}
+
+		for _, node := range batch {
+			tflog.Info(ctx, fmt.Sprintf("starting node %s", node.Label))
+			if err := client.Node.Start(ctx, start.lab.ID, node.ID); err != nil {
+				diags.AddError(
+					common.ErrorLabel,
+					fmt.Sprintf("Unable to start node %s, got error: %s", node.Label, err),
+				)
+				return
+			}
+		}
+		common.Converge(ctx, client, diags, string(start.lab.ID), timeout)
+		if diags.HasError() {
+			return
+		}
+	}
+	tflog.Info(ctx, "restarting nodes done")
+}
@@ -0,0 +1,67 @@
+package lifecycle
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/rschmied/gocmlclient/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangedRestartKeys(t *testing.T) {
+	triggers := func(m map[string]string) types.Map {
+		elems := map[string]attr.Value{}
+		for k, v := range m {
+			elems[k] = types.StringValue(v)
+		}
+		return types.MapValueMust(types.StringType, elems)
+	}
+
+	old := triggers(map[string]string{"r1": "1", "tag:edge": "a", "r2": "x"})
+	assert.Empty(t, changedRestartKeys(old, old))
+	assert.Empty(t, changedRestartKeys(old, types.MapNull(types.StringType)))
+	assert.Empty(t, changedRestartKeys(old, triggers(map[string]string{"r1": "1"})))
+	assert.Equal(t,
+		[]string{"r3", "tag:edge"},
+		changedRestartKeys(old, triggers(map[string]string{"r1": "1", "tag:edge": "b", "r2": "x", "r3": "1"})),
+	)
+	assert.Equal(t,
+		[]string{"r1"},
+		changedRestartKeys(types.MapNull(types.StringType), triggers(map[string]string{"r1": "1"})),
+	)
+}
+
+func TestRestartBatches(t *testing.T) {
+	lab := &models.Lab{
+		Nodes: models.NodeMap{
+			"n1": {ID: "n1", Label: "core", Tags: []string{"infra"}, State: models.NodeStateBooted},
+			"n2": {ID: "n2", Label: "leaf-1", Tags: []string{"fabric", "edge"}, State: models.NodeStateBooted},
+			"n3": {ID: "n3", Label: "leaf-2", Tags: []string{"fabric", "edge"}, State: models.NodeStateStopped},
+			"n4": {ID: "n4", Label: "host", Tags: []string{"edge"}, State: models.NodeStateStarted},
+		},
+	}
+
+	labels := func(batches [][]*models.Node) [][]string {
+		result := [][]string{}
+		for _, batch := range batches {
+			names := []string{}
+			for _, node := range batch {
+				names = append(names, node.Label)
+			}
+			result = append(result, names)
+		}
+		return result
+	}
+
+	// stopped nodes are not restarted, batches are in staging order
+	assert.Equal(t,
+		[][]string{{"core"}, {"leaf-1"}, {"host"}},
+		labels(restartBatches(lab, []string{"core", "tag:edge"}, []string{"infra", "fabric"})),
+	)
+	assert.Equal(t,
+		[][]string{{"host", "leaf-1"}},
+		labels(restartBatches(lab, []string{"tag:edge"}, nil)),
+	)
+	assert.Empty(t, restartBatches(lab, []string{"leaf-2"}, nil))
+}
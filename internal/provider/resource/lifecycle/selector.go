@@ -0,0 +1,59 @@
+package lifecycle
+
+import (
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// tagKeyPrefix marks keys which select nodes by tag instead of by label.
+const tagKeyPrefix = "tag:"
+
+// isGlobKey reports whether the key is a glob pattern on the node label, as
+// in "leaf-*".
+func isGlobKey(key string) bool {
+	return !strings.HasPrefix(key, tagKeyPrefix) && strings.ContainsAny(key, "*?[")
+}
+
+// isSelectorKey reports whether the key selects nodes by tag or by a glob
+// pattern, other keys are node labels.
+func isSelectorKey(key string) bool {
+	return strings.HasPrefix(key, tagKeyPrefix) || isGlobKey(key)
+}
+
+// nodeMatchesKey reports whether the node is selected by the key, which is
+// either a node label, a glob pattern on the node label or a tag prefixed
+// with "tag:".
+func nodeMatchesKey(label string, tags []string, key string) bool {
+	if tag, ok := strings.CutPrefix(key, tagKeyPrefix); ok {
+		return slices.Contains(tags, tag)
+	}
+	if label == key {
+		return true
+	}
+	if isGlobKey(key) {
+		matched, err := path.Match(key, label)
+		return err == nil && matched
+	}
+	return false
+}
+
+// selectKey returns the key which applies to the node. The node label takes
+// precedence over glob patterns which take precedence over tags. If several
+// patterns or tags match then the first one in sort order is used.
+func selectKey(label string, tags []string, keys []string) (string, bool) {
+	if slices.Contains(keys, label) {
+		return label, true
+	}
+	sorted := slices.Clone(keys)
+	sort.Strings(sorted)
+	for _, glob := range []bool{true, false} {
+		for _, key := range sorted {
+			if isGlobKey(key) == glob && isSelectorKey(key) && nodeMatchesKey(label, tags, key) {
+				return key, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,247 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/rschmied/gocmlclient/pkg/models"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+)
+
+// serverStagingMinVersion is the minimum controller version which supports
+// lab node staging.
+const serverStagingMinVersion = ">=2.10.0"
+
+// stagingBackupKey is the private state key of the values which server-side
+// staging has overwritten.
+const stagingBackupKey = "server_staging"
+
+// privateState is the private state of the resource, as in the requests and
+// responses of the resource operations.
+type privateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// stagingBackup records the node priorities and the lab node staging before
+// server-side staging has changed them, so that they can be restored. A nil
+// priority or node staging was not set.
+type stagingBackup struct {
+	Recorded    bool                 `json:"recorded"`
+	NodeStaging *models.NodeStaging  `json:"node_staging"`
+	Priorities  map[models.UUID]*int `json:"priorities"`
+}
+
+// newStagingBackup returns an empty staging backup.
+func newStagingBackup() *stagingBackup {
+	return &stagingBackup{Priorities: map[models.UUID]*int{}}
+}
+
+// getStagingBackup returns the recorded staging backup from the private
+// state, an empty backup if there is none.
+func getStagingBackup(ctx context.Context, private privateState, diags *diag.Diagnostics) *stagingBackup {
+	backup := newStagingBackup()
+	value, d := private.GetKey(ctx, stagingBackupKey)
+	diags.Append(d...)
+	if len(value) == 0 {
+		return backup
+	}
+	if err := json.Unmarshal(value, backup); err != nil {
+		diags.AddError(common.ErrorLabel, fmt.Sprintf("Unable to read the staging backup, got error: %s", err))
+	}
+	if backup.Priorities == nil {
+		backup.Priorities = map[models.UUID]*int{}
+	}
+	return backup
+}
+
+// setStagingBackup records the staging backup in the private state, an empty
+// backup removes it.
+func setStagingBackup(ctx context.Context, private privateState, backup *stagingBackup, diags *diag.Diagnostics) {
+	if !backup.Recorded {
+		diags.Append(private.SetKey(ctx, stagingBackupKey, nil)...)
+		return
+	}
+	value, err := json.Marshal(backup)
+	if err != nil {
+		diags.AddError(common.ErrorLabel, fmt.Sprintf("Unable to record the staging backup, got error: %s", err))
+		return
+	}
+	diags.Append(private.SetKey(ctx, stagingBackupKey, value)...)
+}
+
+// recordPriority records the priority of the node unless it is already
+// recorded, e.g. by a previous apply.
+func (b *stagingBackup) recordPriority(node *models.Node) {
+	if _, ok := b.Priorities[node.ID]; ok {
+		return
+	}
+	b.Priorities[node.ID] = node.Priority
+}
+
+// recordNodeStaging records the lab node staging unless it is already
+// recorded.
+func (b *stagingBackup) recordNodeStaging(lab *models.Lab) {
+	if b.Recorded {
+		return
+	}
+	b.Recorded = true
+	b.NodeStaging = lab.NodeStaging
+}
+
+// stagePriorities returns the node priorities derived from the stage order,
+// nodes of earlier stages get lower values. A node is assigned to the first
+// stage matching one of its tags, nodes without a matching tag are not
+// included.
+func stagePriorities(lab *models.Lab, stages []string) map[models.UUID]int {
+	priorities := map[models.UUID]int{}
+	for _, node := range lab.Nodes {
+		if node == nil {
+			continue
+		}
+		for idx, stage := range stages {
+			if slices.Contains(node.Tags, stage) {
+				priorities[node.ID] = idx + 1
+				break
+			}
+		}
+	}
+	return priorities
+}
+
+// stagingMode returns the configured staging mode, defaults to client as
+// server-side staging changes node priorities and the lab node staging.
+func stagingMode(staging *labLifecycleStaging) string {
+	if staging.Mode.IsNull() || staging.Mode.IsUnknown() {
+		return cmlschema.StagingModeClient
+	}
+	return staging.Mode.ValueString()
+}
+
+// serverStagingSupported reports whether the controller supports lab node
+// staging.
+func (r *LabLifecycleResource) serverStagingSupported(ctx context.Context) (bool, error) {
+	// Ensure client knows server version (SkipReadyCheck is used at init).
+	if err := r.cfg.Client().System.Ready(ctx); err != nil {
+		return false, err
+	}
+	return r.cfg.Client().System.VersionCheck(ctx, serverStagingMinVersion)
+}
+
+// serverStaging delegates staging to the controller if the staging mode and
+// the controller version permit, the node priorities are set from the stage
+// order and the lab node staging is enabled before the lab is started. The
+// previous values are recorded in start.backup. The result is false when the
+// client-side staging has to be used instead.
+func (r *LabLifecycleResource) serverStaging(ctx context.Context, diags *diag.Diagnostics, start startData) bool {
+	mode := stagingMode(start.staging)
+	if mode == cmlschema.StagingModeClient {
+		return false
+	}
+
+	supported, err := r.serverStagingSupported(ctx)
+	switch {
+	case err != nil && mode == cmlschema.StagingModeServer:
+		diags.AddError(common.ErrorLabel, fmt.Sprintf("Unable to check CML version, got error: %s", err))
+		return true
+	case err != nil:
+		tflog.Warn(ctx, "staging: unable to check CML version, using client-side staging", map[string]any{"error": err.Error()})
+		return false
+	case !supported && mode == cmlschema.StagingModeServer:
+		diags.AddError(common.ErrorLabel, fmt.Sprintf("server-side staging requires CML %s (detected %s)", serverStagingMinVersion, r.cfg.Client().System.Version()))
+		return true
+	case !supported:
+		tflog.Info(ctx, "staging: server-side staging not supported, using client-side staging")
+		return false
+	}
+
+	stages := []string{}
+	for _, elem := range start.staging.Stages.Elements() {
+		stages = append(stages, elem.(types.String).ValueString())
+	}
+	for id, priority := range stagePriorities(start.lab, stages) {
+		node := *start.lab.Nodes[id]
+		if node.Priority != nil && *node.Priority == priority {
+			continue
+		}
+		start.backup.recordNodeStaging(start.lab)
+		start.backup.recordPriority(&node)
+		node.Priority = &priority
+		if _, err := r.cfg.Client().Node.Update(ctx, node); err != nil {
+			diags.AddError(
+				common.ErrorLabel,
+				fmt.Sprintf("Unable to set priority of node %s, got error: %s", node.Label, err),
+			)
+			return true
+		}
+	}
+
+	start.backup.recordNodeStaging(start.lab)
+	_, err = r.cfg.Client().Lab.Update(ctx, start.lab.ID, models.LabUpdateRequest{
+		NodeStaging: &models.NodeStaging{
+			Enabled:        true,
+			StartRemaining: start.staging.StartRemaining.ValueBool(),
+			AbortOnFailure: start.staging.AbortOnFailure.ValueBool(),
+		},
+	})
+	if err != nil {
+		diags.AddError(
+			common.ErrorLabel,
+			fmt.Sprintf("Unable to enable lab node staging, got error: %s", err),
+		)
+		return true
+	}
+
+	tflog.Info(ctx, "staging: delegated to the controller", map[string]any{"stages": stages})
+	r.startNodesAll(ctx, diags, start)
+	return true
+}
+
+// restoreStaging restores the node priorities and the lab node staging which
+// server-side staging has overwritten. Nodes which no longer exist are
+// skipped, restored values are removed from the backup.
+func (r *LabLifecycleResource) restoreStaging(ctx context.Context, diags *diag.Diagnostics, lab *models.Lab, backup *stagingBackup) {
+	if !backup.Recorded {
+		return
+	}
+	for id, priority := range backup.Priorities {
+		current, ok := lab.Nodes[id]
+		if !ok || current == nil {
+			delete(backup.Priorities, id)
+			continue
+		}
+		node := *current
+		node.Priority = priority
+		if _, err := r.cfg.Client().Node.Update(ctx, node); err != nil {
+			diags.AddError(
+				common.ErrorLabel,
+				fmt.Sprintf("Unable to restore priority of node %s, got error: %s", node.Label, err),
+			)
+			return
+		}
+		delete(backup.Priorities, id)
+	}
+
+	// without a previous node staging, it is disabled
+	nodeStaging := backup.NodeStaging
+	if nodeStaging == nil {
+		nodeStaging = &models.NodeStaging{}
+	}
+	_, err := r.cfg.Client().Lab.Update(ctx, lab.ID, models.LabUpdateRequest{NodeStaging: nodeStaging})
+	if err != nil {
+		diags.AddError(
+			common.ErrorLabel,
+			fmt.Sprintf("Unable to restore lab node staging, got error: %s", err),
+		)
+		return
+	}
+	*backup = *newStagingBackup()
+	tflog.Info(ctx, "staging: restored node priorities and lab node staging")
+}
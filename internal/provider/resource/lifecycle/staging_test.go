@@ -0,0 +1,85 @@
+package lifecycle
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/rschmied/gocmlclient/pkg/models"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+)
+
+func TestStagePriorities(t *testing.T) {
+	lab := &models.Lab{
+		Nodes: models.NodeMap{
+			"n1": {ID: "n1", Label: "core", Tags: []string{"infra"}},
+			"n2": {ID: "n2", Label: "leaf", Tags: []string{"edge", "fabric"}},
+			"n3": {ID: "n3", Label: "host", Tags: []string{"hosts"}},
+			"n4": {ID: "n4", Label: "ext"},
+			"n5": nil,
+		},
+	}
+
+	assert.Equal(t,
+		map[models.UUID]int{"n1": 1, "n2": 2},
+		stagePriorities(lab, []string{"infra", "fabric", "edge"}),
+	)
+	assert.Equal(t,
+		map[models.UUID]int{"n1": 2, "n2": 1, "n3": 3},
+		stagePriorities(lab, []string{"edge", "infra", "hosts", "fabric"}),
+	)
+	assert.Empty(t, stagePriorities(lab, nil))
+}
+
+func TestStagingMode(t *testing.T) {
+	assert.Equal(t, cmlschema.StagingModeClient, stagingMode(&labLifecycleStaging{Mode: types.StringNull()}))
+	assert.Equal(t, cmlschema.StagingModeAuto, stagingMode(&labLifecycleStaging{Mode: types.StringValue("auto")}))
+}
+
+type testPrivateState map[string][]byte
+
+func (p testPrivateState) GetKey(_ context.Context, key string) ([]byte, diag.Diagnostics) {
+	return p[key], nil
+}
+
+func (p testPrivateState) SetKey(_ context.Context, key string, value []byte) diag.Diagnostics {
+	if len(value) == 0 {
+		delete(p, key)
+		return nil
+	}
+	p[key] = value
+	return nil
+}
+
+func TestStagingBackup(t *testing.T) {
+	ctx := context.TODO()
+	diags := diag.Diagnostics{}
+	private := testPrivateState{}
+
+	backup := getStagingBackup(ctx, private, &diags)
+	assert.False(t, backup.Recorded)
+
+	// the first recorded values are kept, later ones are set by staging
+	prio := 7
+	staging := &models.NodeStaging{Enabled: true}
+	backup.recordNodeStaging(&models.Lab{NodeStaging: staging})
+	backup.recordPriority(&models.Node{ID: "n1", Priority: &prio})
+	backup.recordPriority(&models.Node{ID: "n2"})
+	backup.recordNodeStaging(&models.Lab{})
+	backup.recordPriority(&models.Node{ID: "n1"})
+
+	setStagingBackup(ctx, private, backup, &diags)
+	got := getStagingBackup(ctx, private, &diags)
+	assert.False(t, diags.HasError())
+	assert.True(t, got.Recorded)
+	assert.Equal(t, staging, got.NodeStaging)
+	assert.Equal(t, map[models.UUID]*int{"n1": &prio, "n2": nil}, got.Priorities)
+
+	// an empty backup removes the key
+	setStagingBackup(ctx, private, newStagingBackup(), &diags)
+	assert.Empty(t, private)
+}
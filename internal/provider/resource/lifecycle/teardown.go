@@ -0,0 +1,160 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/rschmied/gocmlclient/pkg/models"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+)
+
+// teardownData holds what's needed to gracefully shut down a lab.
+type teardownData struct {
+	lab      *models.Lab
+	staging  *labLifecycleStaging
+	deadline time.Time
+}
+
+// remaining returns the time left until the teardown deadline as a duration
+// string suitable for common.Converge.
+func (t teardownData) remaining() string {
+	return time.Until(t.deadline).Round(time.Second).String()
+}
+
+// nodeIsRunning reports whether the node has to be stopped before it can be
+// wiped.
+func nodeIsRunning(node *models.Node) bool {
+	return node.State != models.NodeStateDefined && node.State != models.NodeStateStopped
+}
+
+// shutdownOrder returns the lab nodes in batches, reversing the staged start
+// sequence: nodes not matched by any stage (started last) come first, then
+// the stages in reverse order. A node with tags matching multiple stages was
+// started with the first matching stage and is therefore stopped with it.
+// Nodes within a batch are sorted by label.
+func shutdownOrder(lab *models.Lab, stages []string) [][]*models.Node {
+	batches := make([][]*models.Node, len(stages)+1)
+	stageIndex := make(map[string]int, len(stages))
+	for idx, stage := range stages {
+		if _, ok := stageIndex[stage]; !ok {
+			stageIndex[stage] = idx
+		}
+	}
+
+	for _, node := range lab.Nodes {
+		if node == nil {
+			continue
+		}
+		first := len(stages)
+		for _, tag := range node.Tags {
+			if idx, ok := stageIndex[tag]; ok && idx < first {
+				first = idx
+			}
+		}
+		// batch 0 holds the remaining nodes, stage n goes into batch len-n
+		batch := 0
+		if first < len(stages) {
+			batch = len(stages) - first
+		}
+		batches[batch] = append(batches[batch], node)
+	}
+
+	result := make([][]*models.Node, 0, len(batches))
+	for _, batch := range batches {
+		if len(batch) == 0 {
+			continue
+		}
+		sort.Slice(batch, func(i, j int) bool {
+			return batch[i].Label < batch[j].Label
+		})
+		result = append(result, batch)
+	}
+	return result
+}
+
+// stopStaged stops the nodes of the lab in reverse staging order, waiting for
+// convergence after each batch.
+func (r *LabLifecycleResource) stopStaged(ctx context.Context, diags *diag.Diagnostics, td teardownData) {
+	stages := make([]string, 0, len(td.staging.Stages.Elements()))
+	for _, stageElem := range td.staging.Stages.Elements() {
+		stages = append(stages, stageElem.(types.String).ValueString())
+	}
+
+	for _, batch := range shutdownOrder(td.lab, stages) {
+		stopped := 0
+		for _, node := range batch {
+			if !nodeIsRunning(node) {
+				continue
+			}
+			tflog.Info(ctx, fmt.Sprintf("stopping node %s", node.Label))
+			err := r.cfg.Client().Node.Stop(ctx, td.lab.ID, node.ID)
+			if err != nil {
+				diags.AddError(
+					common.ErrorLabel,
+					fmt.Sprintf("Unable to stop node %s, got error: %s", node.Label, err),
+				)
+				return
+			}
+			stopped++
+		}
+		if stopped > 0 {
+			common.Converge(ctx, r.cfg.Client(), diags, string(td.lab.ID), td.remaining())
+			if diags.HasError() {
+				return
+			}
+		}
+	}
+}
+
+// teardown gracefully shuts down the lab: stop the nodes in reverse staging
+// order, stop and wipe the lab. All waits
+// for convergence are bounded by the deadline.
+func (r *LabLifecycleResource) teardown(ctx context.Context, diags *diag.Diagnostics, td teardownData) {
+	id := string(td.lab.ID)
+
+	if td.lab.State == models.LabStateStarted || td.lab.Running() {
+		if td.staging != nil {
+			r.stopStaged(ctx, diags, td)
+			if diags.HasError() {
+				return
+			}
+		}
+		r.stop(ctx, diags, id)
+		if diags.HasError() {
+			return
+		}
+		common.Converge(ctx, r.cfg.Client(), diags, id, td.remaining())
+		if diags.HasError() {
+			return
+		}
+	}
+
+	if td.lab.State != models.LabStateDefined {
+		r.wipe(ctx, diags, id)
+		if diags.HasError() {
+			return
+		}
+		common.Converge(ctx, r.cfg.Client(), diags, id, td.remaining())
+	}
+}
+
+// newTeardownData collects the teardown settings from the lifecycle state.
+func newTeardownData(lab *models.Lab, staging *labLifecycleStaging, timeout string, diags *diag.Diagnostics) teardownData {
+	td := teardownData{
+		lab:     lab,
+		staging: staging,
+	}
+	tov, err := time.ParseDuration(timeout)
+	if err != nil {
+		diags.AddError(common.ErrorLabel, fmt.Sprintf("can't parse timeout %q: %s", timeout, err))
+		return td
+	}
+	td.deadline = time.Now().Add(tov)
+	return td
+}
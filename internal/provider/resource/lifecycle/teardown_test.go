@@ -0,0 +1,70 @@
+package lifecycle
+
+import (
+	"testing"
+
+	"github.com/rschmied/gocmlclient/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdownOrder(t *testing.T) {
+	lab := &models.Lab{
+		Nodes: models.NodeMap{
+			models.UUID("n1"): {ID: models.UUID("n1"), Label: "core", Tags: []string{"infra"}},
+			models.UUID("n2"): {ID: models.UUID("n2"), Label: "leaf-2", Tags: []string{"fabric"}},
+			models.UUID("n3"): {ID: models.UUID("n3"), Label: "leaf-1", Tags: []string{"fabric"}},
+			models.UUID("n4"): {ID: models.UUID("n4"), Label: "host", Tags: []string{"other"}},
+			models.UUID("n5"): {ID: models.UUID("n5"), Label: "edge", Tags: []string{"fabric", "infra"}},
+			models.UUID("n6"): {ID: models.UUID("n6"), Label: "ext"},
+		},
+	}
+
+	labels := func(batches [][]*models.Node) [][]string {
+		result := [][]string{}
+		for _, batch := range batches {
+			names := []string{}
+			for _, node := range batch {
+				names = append(names, node.Label)
+			}
+			result = append(result, names)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name   string
+		stages []string
+		want   [][]string
+	}{
+		{
+			name:   "no stages",
+			stages: nil,
+			want:   [][]string{{"core", "edge", "ext", "host", "leaf-1", "leaf-2"}},
+		},
+		{
+			name:   "reverse stage order, remaining first",
+			stages: []string{"infra", "fabric"},
+			want: [][]string{
+				{"ext", "host"},
+				{"leaf-1", "leaf-2"},
+				{"core", "edge"},
+			},
+		},
+		{
+			name:   "empty stages are skipped",
+			stages: []string{"infra", "unused", "fabric", "other"},
+			want: [][]string{
+				{"ext"},
+				{"host"},
+				{"leaf-1", "leaf-2"},
+				{"core", "edge"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, labels(shutdownOrder(lab, tt.stages)))
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/rschmied/gocmlclient/pkg/models"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/topology"
+)
+
+// topologyChanged reports whether the topology or the configurations which
+// are injected into the topology nodes differ.
+func topologyChanged(old, new *cmlschema.LabLifecycleModel) bool {
+	return !old.Topology.Equal(new.Topology) ||
+		!old.Configs.Equal(new.Configs) ||
+		!old.NamedConfigs.Equal(new.NamedConfigs) ||
+		!old.ConfigTemplates.Equal(new.ConfigTemplates) ||
+		!old.ConfigVars.Equal(new.ConfigVars)
+}
+
+// nodeLabels maps the lab node IDs in state to the node labels. Configuration
+// keys can be lab node IDs, they are resolved with it.
+func nodeLabels(ctx context.Context, state *cmlschema.LabLifecycleModel, diags *diag.Diagnostics) map[string]string {
+	labels := map[string]string{}
+	if state.Nodes.IsNull() || state.Nodes.IsUnknown() {
+		return labels
+	}
+	nodes := map[string]cmlschema.NodeModel{}
+	diags.Append(state.Nodes.ElementsAs(ctx, &nodes, false)...)
+	for id, node := range nodes {
+		labels[id] = node.Label.ValueString()
+	}
+	return labels
+}
+
+// effectiveTopology parses the topology of the lifecycle resource and applies
+// the configs and named_configs, so that the result reflects the node
+// configurations as they are injected on create. Lab node ID keys are
+// resolved with labels, see nodeLabels.
+func effectiveTopology(ctx context.Context, data *cmlschema.LabLifecycleModel, labels map[string]string, diags *diag.Diagnostics) *topology.Topology {
+	topo, err := topology.Parse(data.Topology.ValueString())
+	if err != nil {
+		diags.AddError(common.ErrorLabel, fmt.Sprintf("Unable to parse topology, got error: %s", err))
+		return nil
+	}
+
+	ids := make(map[string]models.UUID, len(labels))
+	for id, label := range labels {
+		ids[label] = models.UUID(id)
+	}
+
+	for idx := range topo.Nodes {
+		node := &topo.Nodes[idx]
+		target := topologyTarget(topo, node, data)
+		target.id = ids[node.Label]
+		config, ok := resolveNodeConfig(ctx, data, target, diags)
+		if !ok {
+			continue
+		}
+		if config.named == nil {
+			node.Configuration = config.config
+			continue
+		}
+		configs := []any{}
+		for _, nc := range config.named {
+			configs = append(configs, map[string]any{"name": nc.Name, "content": nc.Content})
+		}
+		node.Configuration = configs
+	}
+	return topo
+}
+
+// planTopologyDiff computes the difference between the topology in state and
+// the configured one. The node states are not known at plan time, all nodes
+// are assumed to be wiped. Nodes which are not wiped are replaced at apply
+// time instead of being updated which doesn't change the planned result.
+func planTopologyDiff(ctx context.Context, state, config *cmlschema.LabLifecycleModel, diags *diag.Diagnostics) *topology.Diff {
+	labels := nodeLabels(ctx, state, diags)
+	oldTopo := effectiveTopology(ctx, state, labels, diags)
+	newTopo := effectiveTopology(ctx, config, labels, diags)
+	if diags.HasError() {
+		return nil
+	}
+	return topology.Compare(oldTopo, newTopo, nil)
+}
+
+// applyTopology applies the difference between the topology in state and the
+// planned topology to the lab in place, see common.ApplyTopologyDiff.
+func (r *LabLifecycleResource) applyTopology(ctx context.Context, diags *diag.Diagnostics, state, plan *cmlschema.LabLifecycleModel, timeout string) {
+	tflog.Info(ctx, "applying topology changes")
+
+	labels := nodeLabels(ctx, state, diags)
+	oldTopo := effectiveTopology(ctx, state, labels, diags)
+	newTopo := effectiveTopology(ctx, plan, labels, diags)
+	if diags.HasError() {
+		return
+	}
+
+	client := r.cfg.Client()
+	labID := models.UUID(plan.LabID.ValueString())
+	lab, err := client.Lab.GetByID(ctx, labID, true)
+	if err != nil {
+		diags.AddError(common.ErrorLabel, fmt.Sprintf("Unable to fetch lab, got error: %s", err))
+		return
+	}
+
+	byLabel := map[string]*models.Node{}
+	for _, node := range lab.Nodes {
+		if node != nil {
+			byLabel[node.Label] = node
+		}
+	}
+
+	diff := topology.Compare(oldTopo, newTopo, func(label string) bool {
+		node, ok := byLabel[label]
+		return ok && node.State == models.NodeStateDefined
+	})
+	if !diff.Supported() {
+		diags.AddError(common.ErrorLabel, fmt.Sprintf("Unable to apply topology changes in place: %s", strings.Join(diff.Unsupported, ", ")))
+		return
+	}
+	tflog.Info(ctx, "topology diff", map[string]any{
+		"lab":              diff.Lab,
+		"add_nodes":        len(diff.AddNodes),
+		"remove_nodes":     len(diff.RemoveNodes),
+		"update_nodes":     len(diff.UpdateNodes),
+		"add_links":        len(diff.AddLinks),
+		"remove_links":     len(diff.RemoveLinks),
+		"add_annotations":  len(diff.AddAnnotations),
+		"lab_state":        lab.State,
+		"node_count_prior": len(lab.Nodes),
+	})
+
+	common.ApplyTopologyDiff(ctx, r.cfg, diags, &lab, newTopo, diff, timeout)
+	tflog.Info(ctx, "applying topology changes done")
+}
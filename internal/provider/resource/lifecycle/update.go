@@ -37,6 +37,41 @@ func (r LabLifecycleResource) Update(ctx context.Context, req resource.UpdateReq
 		return
 	}
 
+	// apply topology changes in place, ModifyPlan ensures that only changes
+	// which can be applied in place get here
+	if !planData.Topology.IsNull() && topologyChanged(&stateData, &planData) {
+		timeout := getTimeouts(ctx, req.Config, &resp.Diagnostics).Update.ValueString()
+		r.applyTopology(ctx, &resp.Diagnostics, &stateData, &planData, timeout)
+		if resp.Diagnostics.HasError() {
+			// keep the previous topology in state so that the next plan
+			// computes the remaining changes again but record the nodes
+			// which were actually reached
+			lab, err := r.cfg.Client().Lab.GetByID(ctx, models.UUID(stateData.LabID.ValueString()), true)
+			if err != nil {
+				resp.Diagnostics.AddError(
+					common.ErrorLabel,
+					fmt.Sprintf("Unable to fetch lab after partial topology update, got error: %s", err),
+				)
+				return
+			}
+			stateData.Nodes = r.populateNodes(ctx, &lab, &resp.Diagnostics)
+			stateData.Booted = types.BoolValue(lab.Booted())
+			resp.Diagnostics.Append(resp.State.Set(ctx, stateData)...)
+			return
+		}
+	}
+
+	// place the nodes without coordinates, ModifyPlan plans their x/y as
+	// unknown
+	if layoutData := getLayout(ctx, req.Config, &resp.Diagnostics); layoutData != nil {
+		placement := newLayoutPlacement(ctx, req.Private, planData.Topology, &resp.Diagnostics)
+		r.applyLayout(ctx, &resp.Diagnostics, models.UUID(planData.LabID.ValueString()), layoutData, placement)
+		placement.save(ctx, resp.Private, &resp.Diagnostics)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	desired := models.LabState(planData.State.ValueString())
 	stateChanged := models.LabState(stateData.State.ValueString()) != desired
 	wait := planData.Wait.IsNull() || planData.Wait.ValueBool()
@@ -52,6 +87,16 @@ func (r LabLifecycleResource) Update(ctx context.Context, req resource.UpdateReq
 		return
 	}
 
+	// restore what server-side staging has changed once it is no longer used
+	backup := getStagingBackup(ctx, req.Private, &resp.Diagnostics)
+	if staging := getStaging(ctx, req.Config, &resp.Diagnostics); staging == nil || stagingMode(staging) == cmlschema.StagingModeClient {
+		r.restoreStaging(ctx, &resp.Diagnostics, &lab, backup)
+		setStagingBackup(ctx, resp.Private, backup, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	// Decide whether to act:
 	// - Explicit lifecycle.state transition, OR
 	// - Dependency drift (node/link state diverged while lifecycle.state stayed
@@ -81,6 +126,7 @@ func (r LabLifecycleResource) Update(ctx context.Context, req resource.UpdateReq
 			staging:  getStaging(ctx, req.Config, &resp.Diagnostics),
 			timeouts: getTimeouts(ctx, req.Config, &resp.Diagnostics),
 			wait:     wait,
+			backup:   backup,
 		}
 
 		reconcileLinks := func(current *models.Lab, want models.LabState) {
@@ -113,6 +159,7 @@ func (r LabLifecycleResource) Update(ctx context.Context, req resource.UpdateReq
 		switch desired {
 		case models.LabStateStarted:
 			r.startNodes(ctx, &resp.Diagnostics, start)
+			setStagingBackup(ctx, resp.Private, start.backup, &resp.Diagnostics)
 			// Explicitly reconcile links: lab/node start is not sufficient when a
 			// link was manually stopped out-of-band.
 			reconcileLinks(&lab, desired)
@@ -120,8 +167,26 @@ func (r LabLifecycleResource) Update(ctx context.Context, req resource.UpdateReq
 				timeout := start.timeouts.Update.ValueString()
 				common.Converge(ctx, r.cfg.Client(), &resp.Diagnostics, planData.LabID.ValueString(), timeout)
 			}
+			// rolling restart of the nodes selected by changed restart
+			// triggers, a state transition starts the nodes anyway
+			if keys := changedRestartKeys(stateData.RestartTriggers, planData.RestartTriggers); !stateChanged && len(keys) > 0 && !resp.Diagnostics.HasError() {
+				current, err := r.cfg.Client().Lab.GetByID(ctx, lab.ID, true)
+				if err != nil {
+					resp.Diagnostics.AddError(
+						common.ErrorLabel,
+						fmt.Sprintf("Unable to fetch lab for restart, got error: %s", err),
+					)
+					return
+				}
+				start.lab = &current
+				r.restartNodes(ctx, &resp.Diagnostics, start, keys, planData.RestartWipe.ValueBool())
+			}
+			if !resp.Diagnostics.HasError() {
+				wait := getWaitForAddresses(ctx, req.Config, &resp.Diagnostics)
+				r.waitForAddresses(ctx, &resp.Diagnostics, planData.LabID.ValueString(), wait, start.timeouts.Update.ValueString())
+			}
 		case models.LabStateStopped:
-			r.stop(ctx, resp.Diagnostics, planData.LabID.ValueString())
+			r.stop(ctx, &resp.Diagnostics, planData.LabID.ValueString())
 			reconcileLinks(&lab, desired)
 			if start.wait {
 				timeout := start.timeouts.Update.ValueString()
@@ -130,13 +195,13 @@ func (r LabLifecycleResource) Update(ctx context.Context, req resource.UpdateReq
 		case models.LabStateDefined:
 			// Wipe requires a stop first if the lab (or any node) is still running.
 			if lab.State == models.LabStateStarted || lab.Running() {
-				r.stop(ctx, resp.Diagnostics, planData.LabID.ValueString())
+				r.stop(ctx, &resp.Diagnostics, planData.LabID.ValueString())
 				if start.wait {
 					timeout := start.timeouts.Update.ValueString()
 					common.Converge(ctx, r.cfg.Client(), &resp.Diagnostics, planData.LabID.ValueString(), timeout)
 				}
 			}
-			r.wipe(ctx, resp.Diagnostics, planData.LabID.ValueString())
+			r.wipe(ctx, &resp.Diagnostics, planData.LabID.ValueString())
 			if start.wait {
 				timeout := start.timeouts.Update.ValueString()
 				common.Converge(ctx, r.cfg.Client(), &resp.Diagnostics, planData.LabID.ValueString(), timeout)
@@ -163,7 +228,9 @@ func (r LabLifecycleResource) Update(ctx context.Context, req resource.UpdateReq
 		planData.State = types.StringValue(string(lab.State))
 	}
 	planData.Nodes = r.populateNodes(ctx, &lab, &resp.Diagnostics)
+	populateAddresses(ctx, &lab, &planData, &resp.Diagnostics)
 	planData.Booted = types.BoolValue(lab.Booted())
+	r.applyAutoStop(ctx, &resp.Diagnostics, &lab, &planData)
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, planData)...)
 	tflog.Info(ctx, "Resource LabLifecycle UPDATE done")
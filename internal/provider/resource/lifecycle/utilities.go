@@ -2,24 +2,28 @@ package lifecycle
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"slices"
 	"sort"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
-	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
-	cmlerrors "github.com/rschmied/gocmlclient/pkg/errors"
 	"github.com/rschmied/gocmlclient/pkg/models"
 
 	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
 	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
 )
 
-func getTimeouts(ctx context.Context, config tfsdk.Config, diags *diag.Diagnostics) *labLifecycleTimeouts {
+// attributeGetter is satisfied by tfsdk.Config, tfsdk.Plan and tfsdk.State so
+// that the helpers below can read from any of them (Delete only has state).
+type attributeGetter interface {
+	GetAttribute(ctx context.Context, p path.Path, target any) diag.Diagnostics
+}
+
+func getTimeouts(ctx context.Context, config attributeGetter, diags *diag.Diagnostics) *labLifecycleTimeouts {
 	// timeouts is optional, if omitted it will result in a nil pointer
 	var timeouts *labLifecycleTimeouts
 	diags.Append(config.GetAttribute(ctx, path.Root("timeouts"), &timeouts)...)
@@ -31,11 +35,15 @@ func getTimeouts(ctx context.Context, config tfsdk.Config, diags *diag.Diagnosti
 			Update: types.StringValue("2h"),
 		}
 	}
+	// delete is optional within timeouts
+	if timeouts.Delete.IsNull() || timeouts.Delete.ValueString() == "" {
+		timeouts.Delete = types.StringValue("2h")
+	}
 	tflog.Info(ctx, fmt.Sprintf("timeouts: %+v", timeouts))
 	return timeouts
 }
 
-func getStaging(ctx context.Context, config tfsdk.Config, diags *diag.Diagnostics) *labLifecycleStaging {
+func getStaging(ctx context.Context, config attributeGetter, diags *diag.Diagnostics) *labLifecycleStaging {
 	var staging *labLifecycleStaging
 	diags.Append(config.GetAttribute(ctx, path.Root("staging"), &staging)...)
 	tflog.Info(ctx, fmt.Sprintf("staging: %+v", staging))
@@ -47,7 +55,7 @@ func getStaging(ctx context.Context, config tfsdk.Config, diags *diag.Diagnostic
 	return staging
 }
 
-func (r *LabLifecycleResource) stop(ctx context.Context, diags diag.Diagnostics, id string) {
+func (r *LabLifecycleResource) stop(ctx context.Context, diags *diag.Diagnostics, id string) {
 	tflog.Info(ctx, "lab stop")
 	err := r.cfg.Client().Lab.Stop(ctx, models.UUID(id))
 	if err != nil {
@@ -60,13 +68,13 @@ func (r *LabLifecycleResource) stop(ctx context.Context, diags diag.Diagnostics,
 	tflog.Info(ctx, "lab stop done")
 }
 
-func (r *LabLifecycleResource) wipe(ctx context.Context, diags diag.Diagnostics, id string) {
+func (r *LabLifecycleResource) wipe(ctx context.Context, diags *diag.Diagnostics, id string) {
 	tflog.Info(ctx, "lab wipe")
 	err := r.cfg.Client().Lab.Wipe(ctx, models.UUID(id))
 	if err != nil {
 		diags.AddError(
 			common.ErrorLabel,
-			fmt.Sprintf("Unable to destroy CML2 lab, got error: %s", err),
+			fmt.Sprintf("Unable to wipe CML2 lab, got error: %s", err),
 		)
 		return
 	}
@@ -108,6 +116,11 @@ func (r *LabLifecycleResource) startNodes(ctx context.Context, diags *diag.Diagn
 		return
 	}
 
+	// let the controller run the stages, if supported
+	if r.serverStaging(ctx, diags, start) {
+		return
+	}
+
 	// start nodes in stages
 	for _, stageElem := range start.staging.Stages.Elements() {
 		stage := stageElem.(types.String).ValueString()
@@ -129,6 +142,11 @@ func (r *LabLifecycleResource) startNodes(ctx context.Context, diags *diag.Diagn
 		// should be: timeout applied to all stages combined
 		timeout := start.timeouts.Create.ValueString()
 		common.Converge(ctx, r.cfg.Client(), diags, string(start.lab.ID), timeout)
+		// start no further stages after a failure, if indicated
+		if start.staging.AbortOnFailure.ValueBool() && diags.HasError() {
+			tflog.Warn(ctx, fmt.Sprintf("staging aborted in stage %s", stage))
+			return
+		}
 	}
 
 	// start remaining nodes, if indicated
@@ -141,7 +159,7 @@ func (r *LabLifecycleResource) startNodes(ctx context.Context, diags *diag.Diagn
 func (r *LabLifecycleResource) injectConfigs(ctx context.Context, lab *models.Lab, data *cmlschema.LabLifecycleModel, diags *diag.Diagnostics) {
 	tflog.Info(ctx, "injectConfigs")
 
-	if len(data.Configs.Elements()) == 0 && len(data.NamedConfigs.Elements()) == 0 {
+	if len(data.Configs.Elements()) == 0 && len(data.NamedConfigs.Elements()) == 0 && len(data.ConfigTemplates.Elements()) == 0 {
 		tflog.Info(ctx, "injectConfigs: no configs")
 		return
 	}
@@ -151,22 +169,44 @@ func (r *LabLifecycleResource) injectConfigs(ctx context.Context, lab *models.La
 		return
 	}
 
-	// inject regular configuration (legacy)
-	for nodeID, config := range data.Configs.Elements() {
-		node, err := lab.NodeByLabel(ctx, nodeID)
-		if errors.Is(err, cmlerrors.ErrElementNotFound) {
-			node = lab.Nodes[models.UUID(nodeID)]
+	// keys are node labels (or node IDs), glob patterns on the node label or
+	// tags prefixed with "tag:", configurations selected by patterns or tags
+	// as well as config_templates are rendered for each node
+	nodes := make([]*models.Node, 0, len(lab.Nodes))
+	for _, node := range lab.Nodes {
+		if node != nil {
+			nodes = append(nodes, node)
 		}
-		if node == nil {
-			diags.AddError(common.ErrorLabel, fmt.Sprintf("node with label %s not found", nodeID))
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Label < nodes[j].Label
+	})
+
+	used := map[string]bool{}
+	for _, node := range nodes {
+		target := configTarget{id: node.ID, data: common.NodeTemplateData(node, lab)}
+		config, ok := resolveNodeConfig(ctx, data, target, diags)
+		if !ok {
 			continue
 		}
+		used[config.key] = true
 		if node.State != models.NodeStateDefined {
 			diags.AddError(common.ErrorLabel, fmt.Sprintf("unexpected node state %s", node.State))
 			continue
 		}
-		configString := config.(types.String).ValueString()
-		err = r.cfg.Client().Node.SetConfig(ctx, node, configString)
+		if config.named != nil {
+			// named configurations (from 2.7.0 and newer)
+			err := r.cfg.Client().Node.SetNamedConfigs(ctx, node, config.named)
+			if err != nil {
+				diags.AddError(
+					"set node named config failed",
+					fmt.Sprintf("setting the new node configurations failed: %s", err),
+				)
+			}
+			continue
+		}
+		// regular configuration (legacy)
+		err := r.cfg.Client().Node.SetConfig(ctx, node, config.config)
 		if err != nil {
 			diags.AddError(
 				"set node config failed",
@@ -175,32 +215,36 @@ func (r *LabLifecycleResource) injectConfigs(ctx context.Context, lab *models.La
 		}
 	}
 
-	// inject named configurations (from 2.7.0 and newer)
-	for nodeID, config := range data.NamedConfigs.Elements() {
-		node, err := lab.NodeByLabel(ctx, nodeID)
-		if errors.Is(err, cmlerrors.ErrElementNotFound) {
-			node = lab.Nodes[models.UUID(nodeID)]
-		}
-		if node == nil {
-			diags.AddError(common.ErrorLabel, fmt.Sprintf("node with label %s not found", nodeID))
+	for _, elements := range []map[string]attr.Value{data.Configs.Elements(), data.NamedConfigs.Elements(), data.ConfigTemplates.Elements()} {
+		checkUnusedKeys(sortedKeys(elements), used, nodes, diags)
+	}
+	tflog.Info(ctx, "injectConfigs: done")
+}
+
+// checkUnusedKeys reports keys which haven't been applied to any node. A tag
+// or glob key which matches nodes that are all configured by more specific
+// keys is only a warning.
+func checkUnusedKeys(keys []string, used map[string]bool, nodes []*models.Node, diags *diag.Diagnostics) {
+	for _, key := range keys {
+		if used[key] {
 			continue
 		}
-		if node.State != models.NodeStateDefined {
-			diags.AddError(common.ErrorLabel, fmt.Sprintf("unexpected node state %s", node.State))
+		if !isSelectorKey(key) {
+			diags.AddError(common.ErrorLabel, fmt.Sprintf("node with label %s not found", key))
 			continue
 		}
-
-		ba := config.(types.List)
-		configs := cmlschema.GetNamedConfigs(ctx, *diags, ba)
-		err = r.cfg.Client().Node.SetNamedConfigs(ctx, node, configs)
-		if err != nil {
-			diags.AddError(
-				"set node named config failed",
-				fmt.Sprintf("setting the new node configurations failed: %s", err),
-			)
+		matched := slices.ContainsFunc(nodes, func(node *models.Node) bool {
+			return nodeMatchesKey(node.Label, node.Tags, key)
+		})
+		if !matched {
+			diags.AddError(common.ErrorLabel, fmt.Sprintf("no node matches %q", key))
+			continue
 		}
+		diags.AddWarning(
+			common.ErrorLabel,
+			fmt.Sprintf("%q is not used, all nodes it matches are configured by more specific keys", key),
+		)
 	}
-	tflog.Info(ctx, "injectConfigs: done")
 }
 
 // labHasDrift reports whether any node or link in lab is not in the state
@@ -0,0 +1,177 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/topology"
+)
+
+const invalidTopology = "Invalid topology"
+
+func addTopologyProblems(diags *diag.Diagnostics, problems []topology.Problem) {
+	for _, problem := range problems {
+		if problem.Warning {
+			diags.AddAttributeWarning(path.Root("topology"), invalidTopology, problem.String())
+			continue
+		}
+		diags.AddAttributeError(path.Root("topology"), invalidTopology, problem.String())
+	}
+}
+
+// topologyHasKey reports whether any node of the topology is selected by the
+// key (a node label or a tag prefixed with "tag:").
+func topologyHasKey(topo *topology.Topology, key string) bool {
+	for _, node := range topo.Nodes {
+		if nodeMatchesKey(node.Label, node.Tags, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateTopology parses the configured topology and runs the checks which
+// do not require the controller: the topology structure itself and the
+// references from configs, named_configs, wait_for_addresses,
+// restart_triggers and staging into the topology. Configuration keys can be
+// node labels or lab node IDs.
+func validateTopology(ctx context.Context, data *cmlschema.LabLifecycleModel, diags *diag.Diagnostics) {
+	if data.Topology.IsNull() || data.Topology.IsUnknown() {
+		return
+	}
+
+	topo, err := topology.Parse(data.Topology.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("topology"), invalidTopology, err.Error())
+		return
+	}
+	addTopologyProblems(diags, topo.Validate())
+
+	for _, attr := range []struct {
+		name  string
+		value types.Map
+	}{
+		{"configs", data.Configs},
+		{"named_configs", data.NamedConfigs},
+		{"config_templates", data.ConfigTemplates},
+	} {
+		if attr.value.IsUnknown() {
+			continue
+		}
+		for key := range attr.value.Elements() {
+			// lab node IDs are assigned by the controller, they can't be
+			// checked against the topology
+			if _, err := uuid.Parse(key); err == nil {
+				continue
+			}
+			switch {
+			case !isSelectorKey(key) && topo.NodeByLabel(key) == nil:
+				diags.AddAttributeError(
+					path.Root(attr.name).AtMapKey(key),
+					invalidTopology,
+					fmt.Sprintf("node with label %s not found in topology", key),
+				)
+			case isSelectorKey(key) && !topologyHasKey(topo, key):
+				diags.AddAttributeError(
+					path.Root(attr.name).AtMapKey(key),
+					invalidTopology,
+					fmt.Sprintf("no node in the topology matches %q", key),
+				)
+			}
+		}
+	}
+
+	// render the configuration templates for all topology nodes, unknown
+	// values are skipped
+	if !data.Configs.IsUnknown() && !data.NamedConfigs.IsUnknown() && !data.ConfigTemplates.IsUnknown() && !data.ConfigVars.IsUnknown() {
+		for idx := range topo.Nodes {
+			target := topologyTarget(topo, &topo.Nodes[idx], data)
+			resolveNodeConfig(ctx, data, target, diags)
+		}
+	}
+
+	if !data.WaitForAddresses.IsNull() && !data.WaitForAddresses.IsUnknown() {
+		var wait labLifecycleWaitForAddresses
+		diags.Append(tfsdk.ValueAs(ctx, data.WaitForAddresses, &wait)...)
+		if !wait.Interfaces.IsUnknown() {
+			for label := range wait.Interfaces.Elements() {
+				if topo.NodeByLabel(label) == nil {
+					diags.AddAttributeError(
+						path.Root("wait_for_addresses").AtName("interfaces").AtMapKey(label),
+						invalidTopology,
+						fmt.Sprintf("node with label %s not found in topology", label),
+					)
+				}
+			}
+		}
+	}
+
+	if !data.RestartTriggers.IsUnknown() {
+		for key := range data.RestartTriggers.Elements() {
+			if !topologyHasKey(topo, key) {
+				diags.AddAttributeError(
+					path.Root("restart_triggers").AtMapKey(key),
+					invalidTopology,
+					fmt.Sprintf("no node in the topology matches %q", key),
+				)
+			}
+		}
+	}
+
+	if data.Staging.IsNull() || data.Staging.IsUnknown() {
+		return
+	}
+	var staging labLifecycleStaging
+	diags.Append(tfsdk.ValueAs(ctx, data.Staging, &staging)...)
+	if diags.HasError() || staging.Stages.IsUnknown() {
+		return
+	}
+	for idx, stageElem := range staging.Stages.Elements() {
+		stage, ok := stageElem.(types.String)
+		if !ok || stage.IsUnknown() {
+			continue
+		}
+		if !topo.HasTag(stage.ValueString()) {
+			diags.AddAttributeWarning(
+				path.Root("staging").AtName("stages").AtListIndex(idx),
+				invalidTopology,
+				fmt.Sprintf("no node in the topology has the tag %q, the stage will be empty", stage.ValueString()),
+			)
+		}
+	}
+}
+
+// validateTopologyDefinitions checks the node and image definitions of the
+// topology against the controller. If the definitions can't be retrieved
+// then the check is skipped.
+func (r *LabLifecycleResource) validateTopologyDefinitions(ctx context.Context, data *cmlschema.LabLifecycleModel, diags *diag.Diagnostics) {
+	if r.cfg == nil || data.Topology.IsNull() || data.Topology.IsUnknown() {
+		return
+	}
+
+	topo, err := topology.Parse(data.Topology.ValueString())
+	if err != nil {
+		// already reported by ValidateConfig
+		return
+	}
+
+	nodeDefs, err := r.cfg.NodeDefinitions(ctx)
+	if err != nil {
+		tflog.Warn(ctx, "can't get node definitions, skipping topology validation", map[string]any{"error": err.Error()})
+		return
+	}
+	imageDefs, err := r.cfg.ImageDefinitions(ctx)
+	if err != nil {
+		tflog.Warn(ctx, "can't get image definitions, skipping image validation", map[string]any{"error": err.Error()})
+		imageDefs = nil
+	}
+	addTopologyProblems(diags, topo.ValidateDefinitions(nodeDefs, imageDefs))
+}
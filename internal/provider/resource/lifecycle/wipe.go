@@ -0,0 +1,52 @@
+package lifecycle
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/rschmied/gocmlclient/pkg/models"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+)
+
+// wipedNodes returns the sorted labels of the nodes which lose their runtime
+// state when the lab is moved to DEFINED_ON_CORE, e.g. all nodes which are
+// not wiped already.
+func wipedNodes(nodes map[string]cmlschema.NodeModel) []string {
+	labels := []string{}
+	for _, node := range nodes {
+		if node.State.IsNull() || node.State.IsUnknown() {
+			continue
+		}
+		if node.State.ValueString() != string(models.NodeStateDefined) {
+			labels = append(labels, node.Label.ValueString())
+		}
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// checkWipe reports the nodes which are wiped by the planned change as a
+// warning, or as an error if wiping is not allowed.
+func checkWipe(diags *diag.Diagnostics, nodes map[string]cmlschema.NodeModel, data *cmlschema.LabLifecycleModel) {
+	labels := wipedNodes(nodes)
+	if len(labels) == 0 {
+		return
+	}
+	detail := fmt.Sprintf(
+		"Moving the lab to %s stops and wipes the following nodes, their runtime state is lost: %s.",
+		models.LabStateDefined, strings.Join(labels, ", "),
+	)
+	if !data.AllowWipe.IsNull() && !data.AllowWipe.ValueBool() {
+		diags.AddAttributeError(
+			path.Root("state"),
+			"Lab wipe not allowed",
+			detail+" Set \"allow_wipe\" to true to allow this change.",
+		)
+		return
+	}
+	diags.AddAttributeWarning(path.Root("state"), "Lab nodes will be wiped", detail)
+}
@@ -0,0 +1,48 @@
+package lifecycle
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+)
+
+func TestCheckWipe(t *testing.T) {
+	node := func(label, state string) cmlschema.NodeModel {
+		return cmlschema.NodeModel{Label: types.StringValue(label), State: types.StringValue(state)}
+	}
+	nodes := map[string]cmlschema.NodeModel{
+		"n1": node("r2", "BOOTED"),
+		"n2": node("r1", "STOPPED"),
+		"n3": node("r3", "DEFINED_ON_CORE"),
+		"n4": {Label: types.StringValue("r4"), State: types.StringUnknown()},
+	}
+	assert.Equal(t, []string{"r1", "r2"}, wipedNodes(nodes))
+
+	tests := []struct {
+		name      string
+		allow     types.Bool
+		nodes     map[string]cmlschema.NodeModel
+		warnings  int
+		wantError bool
+	}{
+		{"default", types.BoolNull(), nodes, 1, false},
+		{"allowed", types.BoolValue(true), nodes, 1, false},
+		{"not allowed", types.BoolValue(false), nodes, 0, true},
+		{"nothing to wipe", types.BoolValue(false), map[string]cmlschema.NodeModel{"n3": node("r3", "DEFINED_ON_CORE")}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			checkWipe(&diags, tt.nodes, &cmlschema.LabLifecycleModel{AllowWipe: tt.allow})
+			assert.Equal(t, tt.wantError, diags.HasError())
+			assert.Equal(t, tt.warnings, diags.WarningsCount())
+			if tt.wantError {
+				assert.Contains(t, diags.Errors()[0].Detail(), "r1, r2")
+			}
+		})
+	}
+}
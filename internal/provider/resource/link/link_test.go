@@ -8,6 +8,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
 
 	cml "github.com/ciscodevnet/terraform-provider-cml2/internal/provider"
@@ -170,9 +171,14 @@ func TestAccLifecycleResourceSlotChange(t *testing.T) {
 				PlanOnly:           true,
 				ExpectNonEmptyPlan: true,
 			},
-			// apply this change
+			// apply this change, the link is moved in place
 			{
 				Config: testAccLinkResourceConfigSlotChange(cfg.Cfg, 1),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("cml2_link.l0", plancheck.ResourceActionUpdate),
+					},
+				},
 				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttr("cml2_link.l0", "slot_a", "1"),
 					resource.TestCheckResourceAttr("cml2_link.l0", "slot_b", "2"),
@@ -2,15 +2,17 @@ package link
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 
 	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
 )
 
-// ModifyPlan enforces replacement semantics for immutable link attributes.
+// ModifyPlan plans slot changes as in-place moves of the link.
 func (r *LinkResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
 	var planData, stateData cmlschema.LinkModel
 
@@ -32,17 +34,39 @@ func (r *LinkResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRe
 		return
 	}
 
-	if !stateData.SlotA.Equal(planData.SlotA) {
-		// Only force replacement when slot is explicitly configured.
-		if !planData.SlotA.IsNull() {
-			resp.RequiresReplace = append(resp.RequiresReplace, path.Root("slot_a"))
+	// Slot changes move the link in place: the controller can't change the
+	// endpoints of a link, Update re-creates it between the same nodes and
+	// in the same state. The link ID, interfaces, label and capture key are
+	// assigned anew, all other attributes are kept.
+	moved := false
+	for _, endpoint := range []struct {
+		attr        string
+		node        string
+		state, plan types.Int64
+	}{
+		{"slot_a", "A", stateData.SlotA, planData.SlotA},
+		{"slot_b", "B", stateData.SlotB, planData.SlotB},
+	} {
+		// Only move the link when the slot is explicitly configured.
+		if endpoint.plan.IsNull() || endpoint.plan.IsUnknown() || endpoint.state.Equal(endpoint.plan) {
+			continue
 		}
+		moved = true
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root(endpoint.attr),
+			"Link will be moved",
+			fmt.Sprintf(
+				"Link %s moves from slot %d to slot %d on node %s. The link is re-created on the controller, its ID, interfaces, label and capture key change.",
+				stateData.ID.ValueString(), endpoint.state.ValueInt64(), endpoint.plan.ValueInt64(), endpoint.node,
+			),
+		)
 	}
-
-	if !stateData.SlotB.Equal(planData.SlotB) {
-		if !planData.SlotB.IsNull() {
-			resp.RequiresReplace = append(resp.RequiresReplace, path.Root("slot_b"))
-		}
+	if moved {
+		planData.ID = types.StringUnknown()
+		planData.InterfaceA = types.StringUnknown()
+		planData.InterfaceB = types.StringUnknown()
+		planData.Label = types.StringUnknown()
+		planData.CaptureKey = types.StringUnknown()
 	}
 
 	resp.Diagnostics.Append(resp.Plan.Set(ctx, &planData)...)
@@ -2,23 +2,128 @@ package link
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/rschmied/gocmlclient/pkg/models"
 
 	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
 )
 
-// Update is a no-op for links (immutable once created).
+// Update moves a link to different slots. As the controller can't change
+// the endpoints of a link, the link is re-created, a started link is started
+// again. If the new link can't be created, the link is restored at the
+// previous slots. All other changes are no-ops.
 func (r LinkResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var data cmlschema.LinkModel
+	var data, stateData cmlschema.LinkModel
 
 	tflog.Info(ctx, "Resource Link UPDATE")
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	tflog.Warn(ctx, "Resource Link UPDATE: not implemented")
+	resp.Diagnostics.Append(req.State.Get(ctx, &stateData)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.SlotA.Equal(stateData.SlotA) && data.SlotB.Equal(stateData.SlotB) {
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		tflog.Info(ctx, "Resource Link UPDATE done")
+		return
+	}
+
+	// see Create for the reason of the lock
+	r.cfg.Lock()
+	defer r.cfg.Unlock()
+
+	labID := models.UUID(data.LabID.ValueString())
+	oldID := models.UUID(stateData.ID.ValueString())
+	tflog.Info(ctx, "moving link", map[string]any{
+		"id": oldID, "slot_a": data.SlotA.ValueInt64(), "slot_b": data.SlotB.ValueInt64(),
+	})
+
+	if err := r.cfg.Client().Link.Delete(ctx, labID, oldID); err != nil && !common.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			common.ErrorLabel,
+			fmt.Sprintf("Unable to remove link %s for move, got error: %s", oldID, err),
+		)
+		return
+	}
+
+	started := stateData.State.ValueString() == string(models.LinkStateStarted)
+	newLink, err := r.createLink(ctx, labID, &data, started)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			common.ErrorLabel,
+			fmt.Sprintf("Unable to re-create moved link, got error: %s", err),
+		)
+		// put the link back where it was
+		newLink, err = r.createLink(ctx, labID, &stateData, started)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				common.ErrorLabel,
+				fmt.Sprintf("Unable to restore link at the previous slots, got error: %s", err),
+			)
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		data = stateData
+	}
+
+	resp.Diagnostics.Append(
+		tfsdk.ValueFrom(
+			ctx,
+			cmlschema.NewLink(ctx, &newLink, &resp.Diagnostics),
+			types.ObjectType{AttrTypes: cmlschema.LinkAttrType},
+			&data,
+		)...,
+	)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 	tflog.Info(ctx, "Resource Link UPDATE done")
 }
+
+// createLink creates the link at the slots of the model and starts it, if
+// indicated. A link which can't be started is removed again.
+func (r LinkResource) createLink(ctx context.Context, labID models.UUID, data *cmlschema.LinkModel, start bool) (models.Link, error) {
+	link := models.Link{
+		LabID:   labID,
+		SrcNode: models.UUID(data.NodeA.ValueString()),
+		DstNode: models.UUID(data.NodeB.ValueString()),
+		SrcSlot: int(data.SlotA.ValueInt64()),
+		DstSlot: int(data.SlotB.ValueInt64()),
+	}
+	newLink, err := r.cfg.Client().Link.Create(ctx, link)
+	if err != nil {
+		return models.Link{}, err
+	}
+
+	if start {
+		started, err := r.startLink(ctx, labID, newLink.ID)
+		if err != nil {
+			// don't leave a half moved link behind
+			if delErr := r.cfg.Client().Link.Delete(ctx, labID, newLink.ID); delErr != nil {
+				tflog.Warn(ctx, "unable to remove link", map[string]any{"id": newLink.ID, "error": delErr.Error()})
+			}
+			return models.Link{}, err
+		}
+		newLink = started
+	}
+
+	// see Create, the API does not reliably echo slot numbers
+	newLink.SrcSlot = link.SrcSlot
+	newLink.DstSlot = link.DstSlot
+	return newLink, nil
+}
+
+// startLink starts the link and returns it as started.
+func (r LinkResource) startLink(ctx context.Context, labID, id models.UUID) (models.Link, error) {
+	if err := r.cfg.Client().Link.Start(ctx, labID, id); err != nil {
+		return models.Link{}, fmt.Errorf("starting link %s: %w", id, err)
+	}
+	return r.cfg.Client().Link.GetByID(ctx, labID, id)
+}
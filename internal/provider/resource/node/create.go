@@ -18,8 +18,8 @@ import (
 // Create creates a new node in a CML lab.
 func (r *NodeResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var (
-		data       cmlschema.NodeModel
-		configData cmlschema.NodeModel
+		data       cmlschema.NodeResourceModel
+		configData cmlschema.NodeResourceModel
 		err        error
 	)
 
@@ -163,6 +163,15 @@ func (r *NodeResource) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
+	// the configuration template depends on values which are only known
+	// once the node exists, like its ID or interfaces
+	if !data.ConfigTemplate.IsNull() && data.Configuration.IsUnknown() {
+		r.applyConfigTemplate(ctx, &resp.Diagnostics, data, &newNode)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	// When named configs are disabled provider-side, normalize any server-returned
 	// named configs back into the single configuration field to avoid state drift.
 	if !r.cfg.UseNamedConfigs() && len(newNode.Configurations) > 0 {
@@ -202,11 +211,13 @@ func (r *NodeResource) Create(ctx context.Context, req resource.CreateRequest, r
 			ctx,
 			cmlschema.NewNode(ctx, &newNode, &resp.Diagnostics),
 			types.ObjectType{AttrTypes: cmlschema.NodeAttrType},
-			&data,
+			&data.NodeModel,
 		)...,
 	)
 	data.Generation = plannedGeneration
 
+	r.waitForAddresses(ctx, &resp.Diagnostics, &data, newNode.State)
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 
 	tflog.Info(ctx, "Resource Node CREATE done")
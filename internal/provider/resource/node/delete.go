@@ -15,7 +15,7 @@ import (
 // Delete deletes an existing node from a CML lab.
 func (r *NodeResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	var (
-		data cmlschema.NodeModel
+		data cmlschema.NodeResourceModel
 		err  error
 	)
 
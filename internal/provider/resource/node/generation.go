@@ -30,6 +30,8 @@ type generationPayload struct {
 	CPUlimit        *int64                  `json:"cpu_limit"`
 	BootDiskSize    *int64                  `json:"boot_disk_size"`
 	DataVolume      *int64                  `json:"data_volume"`
+	ConfigTemplate  *string                 `json:"config_template,omitempty"`
+	ConfigVars      map[string]string       `json:"config_vars,omitempty"`
 }
 
 func ptrString(v types.String) *string {
@@ -69,8 +71,8 @@ func normalizedConfigs(ctx context.Context, data cmlschema.NodeModel) ([]normali
 	return out, nil
 }
 
-func generationFromNodeModel(ctx context.Context, data cmlschema.NodeModel) (types.String, error) {
-	cfgs, err := normalizedConfigs(ctx, data)
+func generationFromNodeModel(ctx context.Context, data cmlschema.NodeResourceModel) (types.String, error) {
+	cfgs, err := normalizedConfigs(ctx, data.NodeModel)
 	if err != nil {
 		return types.StringNull(), err
 	}
@@ -85,12 +87,20 @@ func generationFromNodeModel(ctx context.Context, data cmlschema.NodeModel) (typ
 		CPUlimit:        ptrInt64(data.CPUlimit),
 		BootDiskSize:    ptrInt64(data.BootDiskSize),
 		DataVolume:      ptrInt64(data.DataVolume),
+		ConfigTemplate:  ptrString(data.ConfigTemplate),
 	}
 	if !data.Configuration.IsNull() && !data.Configuration.IsUnknown() {
 		cfg := data.Configuration.ValueString()
 		payload.Configuration = &cfg
 	}
 
+	if !data.ConfigVars.IsNull() && !data.ConfigVars.IsUnknown() {
+		payload.ConfigVars = map[string]string{}
+		for key, value := range data.ConfigVars.Elements() {
+			payload.ConfigVars[key] = value.(types.String).ValueString()
+		}
+	}
+
 	b, err := json.Marshal(payload)
 	if err != nil {
 		return types.StringNull(), err
@@ -40,12 +40,12 @@ func (r *NodeResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 	resp.Schema.MarkdownDescription = "A node resource represents a CML node. At create time, the lab ID, a " +
 		"node definition and a label must be provided.  Other attributes are optional.  Note that some " +
 		"attributes can't be changed after the node state has changed to `STARTED` (see the `lifecyle` resource) " +
-		"once. Changing attributes will then require a replace.  " +
+		"once. Changing attributes will then require a replace, or a restart (see `restart_on_change`).  " +
 		"Node configurations are \"day zero\" configurations. Replacing a configuration typically requires a " +
 		"node replacement if the node has been started.  No Configurations can be provided for unmanaged switches. " +
 		"External connectors require the connector device name (like \"virbr0\"), not the label (like \"NAT\"). " +
 		"The available connectors can be retrieved via the external connector data source."
-	resp.Schema.Attributes = cmlschema.Node()
+	resp.Schema.Attributes = cmlschema.NodeResource()
 	resp.Diagnostics = nil
 }
 
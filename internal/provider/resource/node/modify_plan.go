@@ -36,7 +36,7 @@ import (
 // ModifyPlan adjusts the planned node state, enforcing replacement when needed.
 func (r *NodeResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
 	// var stateData, planData cmlschema.NodeModel
-	var configData, planData, stateData cmlschema.NodeModel
+	var configData, planData, stateData cmlschema.NodeResourceModel
 
 	tflog.Info(ctx, "Resource Node MODIFYPLAN")
 
@@ -78,14 +78,30 @@ func (r *NodeResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRe
 	// 	"unknown_plan": planData.Configuration.IsUnknown(),
 	// })
 
-	if nodeExists && !stateData.Configuration.Equal(planData.Configuration) {
-		// tflog.Info(ctx, "$$$1")
-		resp.RequiresReplace = append(resp.RequiresReplace, path.Root("configuration"))
+	// a configuration template is rendered into the planned configuration
+	if !configData.ConfigTemplate.IsNull() {
+		if !configData.Configuration.IsNull() || !configData.Configurations.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("config_template"),
+				"Node config conflict",
+				"Can't provide config_template together with configuration or configurations",
+			)
+			return
+		}
+		r.planConfigTemplate(ctx, &resp.Diagnostics, &planData, &stateData, !req.State.Raw.IsNull())
+		if resp.Diagnostics.HasError() {
+			return
+		}
 	}
 
-	if nodeExists && !stateData.Configurations.Equal(planData.Configurations) {
-		// tflog.Info(ctx, "$$$2")
-		resp.RequiresReplace = append(resp.RequiresReplace, path.Root("configurations"))
+	// changes which can only be applied while the node is DEFINED_ON_CORE
+	// replace or restart the node, see restart_on_change
+	if nodeExists {
+		changes := hardwareChanges(&configData, &planData, &stateData)
+		resp.RequiresReplace = append(resp.RequiresReplace, planRestart(&planData, changes, &resp.Diagnostics)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
 	}
 
 	// If explicit named configurations are configured, make the single
@@ -104,9 +120,6 @@ func (r *NodeResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRe
 	}
 
 	if !configData.ImageDefinition.IsNull() && !configData.ImageDefinition.Equal(stateData.ImageDefinition) {
-		if nodeExists {
-			resp.RequiresReplace = append(resp.RequiresReplace, path.Root("imagedefinition"))
-		}
 		planData.ImageDefinition = configData.ImageDefinition
 	}
 	if planData.ImageDefinition.IsUnknown() {
@@ -114,9 +127,6 @@ func (r *NodeResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRe
 	}
 
 	if !configData.RAM.IsNull() && !configData.RAM.Equal(stateData.RAM) {
-		if nodeExists {
-			resp.RequiresReplace = append(resp.RequiresReplace, path.Root("ram"))
-		}
 		planData.RAM = configData.RAM
 	}
 	if planData.RAM.IsUnknown() {
@@ -124,9 +134,6 @@ func (r *NodeResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRe
 	}
 
 	if !configData.CPUs.IsNull() && !configData.CPUs.Equal(stateData.CPUs) {
-		if nodeExists {
-			resp.RequiresReplace = append(resp.RequiresReplace, path.Root("cpus"))
-		}
 		planData.CPUs = configData.CPUs
 	}
 	if planData.CPUs.IsUnknown() {
@@ -134,9 +141,6 @@ func (r *NodeResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRe
 	}
 
 	if !configData.CPUlimit.IsNull() && !configData.CPUlimit.Equal(stateData.CPUlimit) {
-		if nodeExists {
-			resp.RequiresReplace = append(resp.RequiresReplace, path.Root("cpu_limit"))
-		}
 		planData.CPUlimit = configData.CPUlimit
 	}
 	// Some node types (docker-style) return cpu_limit=null, while VM/libvirt
@@ -178,9 +182,6 @@ func (r *NodeResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRe
 	}
 
 	if !configData.DataVolume.IsNull() && !configData.DataVolume.Equal(stateData.DataVolume) {
-		if nodeExists {
-			resp.RequiresReplace = append(resp.RequiresReplace, path.Root("data_volume"))
-		}
 		planData.DataVolume = configData.DataVolume
 	}
 	if planData.DataVolume.IsUnknown() {
@@ -188,9 +189,6 @@ func (r *NodeResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRe
 	}
 
 	if !configData.BootDiskSize.IsNull() && !configData.BootDiskSize.Equal(stateData.BootDiskSize) {
-		if nodeExists {
-			resp.RequiresReplace = append(resp.RequiresReplace, path.Root("boot_disk_size"))
-		}
 		planData.BootDiskSize = configData.BootDiskSize
 	}
 	if planData.BootDiskSize.IsUnknown() {
@@ -199,6 +199,47 @@ func TestAccNodeResourceRecreatesWhenDeletedExternally(t *testing.T) {
 	})
 }
 
+func TestAccNodeResourceRestartOnChange(t *testing.T) {
+	cfg.SkipUnlessAcc(t)
+
+	var nodeID string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNodeResourceConfigRestart(cfg.Cfg, "stop_wipe_restart", 512),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cml2_node.r1", "state", "BOOTED"),
+					resource.TestCheckResourceAttrWith("cml2_node.r1", "id", func(value string) error {
+						nodeID = value
+						return nil
+					}),
+				),
+			},
+			{
+				// the node is restarted in place
+				Config: testAccNodeResourceConfigRestart(cfg.Cfg, "stop_wipe_restart", 1024),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cml2_node.r1", "ram", "1024"),
+					resource.TestCheckResourceAttr("cml2_node.r1", "state", "BOOTED"),
+					resource.TestCheckResourceAttrWith("cml2_node.r1", "id", func(value string) error {
+						if value != nodeID {
+							return fmt.Errorf("expected node %s to be kept, got %s", nodeID, value)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				Config:      testAccNodeResourceConfigRestart(cfg.Cfg, "never", 512),
+				ExpectError: regexp.MustCompile(`requires a restart which is disabled`),
+			},
+		},
+	})
+}
+
 func safeCtx() context.Context {
 	// The upstream client methods accept a context; we keep it simple for the test.
 	return context.Background()
@@ -794,3 +835,23 @@ resource "cml2_node" "ums" {
 }
 `, cfg)
 }
+
+func testAccNodeResourceConfigRestart(cfg, policy string, ram int) string {
+	return fmt.Sprintf(`
+		%[1]s
+		resource "cml2_lab" "test" {
+			title = "acc node restart on change"
+		}
+		resource "cml2_node" "r1" {
+			lab_id            = cml2_lab.test.id
+			label             = "alpine-0"
+			nodedefinition    = "alpine"
+			ram               = %[3]d
+			restart_on_change = %[2]q
+		}
+		resource "cml2_lifecycle" "top" {
+			lab_id     = cml2_lab.test.id
+			depends_on = [cml2_node.r1]
+		}
+		`, cfg, policy, ram)
+}
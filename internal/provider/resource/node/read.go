@@ -15,7 +15,7 @@ import (
 )
 
 func (r *NodeResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	var data cmlschema.NodeModel
+	var data cmlschema.NodeResourceModel
 
 	tflog.Info(ctx, "Resource Node READ")
 
@@ -78,7 +78,7 @@ func (r *NodeResource) Read(ctx context.Context, req resource.ReadRequest, resp
 			ctx,
 			cmlschema.NewNode(ctx, &node, &resp.Diagnostics),
 			types.ObjectType{AttrTypes: cmlschema.NodeAttrType},
-			&data,
+			&data.NodeModel,
 		)...,
 	)
 
@@ -0,0 +1,149 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/rschmied/gocmlclient/pkg/models"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+)
+
+// restartTimeout is the time to wait for the lab to converge when a node is
+// stopped, wiped or started for restart_on_change.
+const restartTimeout = "30m"
+
+// restartPolicy returns the restart_on_change policy of the node.
+func restartPolicy(data *cmlschema.NodeResourceModel) string {
+	if data.RestartOnChange.IsNull() || data.RestartOnChange.IsUnknown() {
+		return cmlschema.RestartOnChangeReplace
+	}
+	return data.RestartOnChange.ValueString()
+}
+
+// hardwareChanges returns the attributes changed by the plan which can only
+// be applied while the node is DEFINED_ON_CORE.
+func hardwareChanges(configData, planData, stateData *cmlschema.NodeResourceModel) []path.Path {
+	changes := []path.Path{}
+	if !stateData.Configuration.Equal(planData.Configuration) {
+		changes = append(changes, path.Root("configuration"))
+	}
+	if !stateData.Configurations.Equal(planData.Configurations) {
+		changes = append(changes, path.Root("configurations"))
+	}
+	for _, item := range []struct {
+		name          string
+		config, state attr.Value
+	}{
+		{"imagedefinition", configData.ImageDefinition, stateData.ImageDefinition},
+		{"ram", configData.RAM, stateData.RAM},
+		{"cpus", configData.CPUs, stateData.CPUs},
+		{"cpu_limit", configData.CPUlimit, stateData.CPUlimit},
+		{"data_volume", configData.DataVolume, stateData.DataVolume},
+		{"boot_disk_size", configData.BootDiskSize, stateData.BootDiskSize},
+	} {
+		if !item.config.IsNull() && !item.config.Equal(item.state) {
+			changes = append(changes, path.Root(item.name))
+		}
+	}
+	return changes
+}
+
+// planRestart applies the restart_on_change policy to the changes of a
+// started node: the node is replaced, restarted or the plan fails.
+func planRestart(planData *cmlschema.NodeResourceModel, changes []path.Path, diags *diag.Diagnostics) []path.Path {
+	if len(changes) == 0 {
+		return nil
+	}
+	names := []string{}
+	for _, change := range changes {
+		names = append(names, change.String())
+	}
+	label := planData.Label.ValueString()
+
+	switch restartPolicy(planData) {
+	case cmlschema.RestartOnChangeNever:
+		diags.AddAttributeError(
+			path.Root("restart_on_change"),
+			common.ErrorLabel,
+			fmt.Sprintf("Node %s has been started, changing %s requires a restart which is disabled by restart_on_change", label, strings.Join(names, ", ")),
+		)
+		return nil
+	case cmlschema.RestartOnChangeStopWipeRestart:
+		diags.AddAttributeWarning(
+			path.Root("restart_on_change"),
+			"Node will be wiped",
+			fmt.Sprintf("Node %s will be stopped and wiped to apply the changes of %s and then started again if it is running, the disks of the node are discarded.", label, strings.Join(names, ", ")),
+		)
+		// the operational data is known after the restart
+		planData.State = types.StringUnknown()
+		planData.Interfaces = types.ListUnknown(types.ObjectType{AttrTypes: cmlschema.InterfaceAttrType})
+		planData.SerialDevices = types.ListUnknown(cmlschema.SerialDevicesAttrType)
+		planData.VNCkey = types.StringUnknown()
+		planData.ComputeID = types.StringUnknown()
+		return nil
+	}
+	return changes
+}
+
+// stopAndWipe stops and wipes the node so that all attributes can be
+// changed.
+func (r *NodeResource) stopAndWipe(ctx context.Context, diags *diag.Diagnostics, node *models.Node, state models.NodeState) {
+	client := r.cfg.Client()
+	if state != models.NodeStateStopped {
+		tflog.Info(ctx, fmt.Sprintf("stopping node %s", node.Label))
+		if err := client.Node.Stop(ctx, node.LabID, node.ID); err != nil {
+			diags.AddError(
+				common.ErrorLabel,
+				fmt.Sprintf("Unable to stop node %s, got error: %s", node.Label, err),
+			)
+			return
+		}
+		common.Converge(ctx, client, diags, string(node.LabID), restartTimeout)
+		if diags.HasError() {
+			return
+		}
+	}
+	tflog.Info(ctx, fmt.Sprintf("wiping node %s", node.Label))
+	if err := client.Node.Wipe(ctx, node.LabID, node.ID); err != nil {
+		diags.AddError(
+			common.ErrorLabel,
+			fmt.Sprintf("Unable to wipe node %s, got error: %s", node.Label, err),
+		)
+		return
+	}
+	common.Converge(ctx, client, diags, string(node.LabID), restartTimeout)
+}
+
+// restart starts the node again and returns it once the lab has converged.
+func (r *NodeResource) restart(ctx context.Context, diags *diag.Diagnostics, node *models.Node) models.Node {
+	client := r.cfg.Client()
+	tflog.Info(ctx, fmt.Sprintf("starting node %s", node.Label))
+	if err := client.Node.Start(ctx, node.LabID, node.ID); err != nil {
+		diags.AddError(
+			common.ErrorLabel,
+			fmt.Sprintf("Unable to start node %s, got error: %s", node.Label, err),
+		)
+		return *node
+	}
+	common.Converge(ctx, client, diags, string(node.LabID), restartTimeout)
+	if diags.HasError() {
+		return *node
+	}
+	started, err := client.Node.GetByID(ctx, node.LabID, node.ID)
+	if err != nil {
+		diags.AddError(
+			common.ErrorLabel,
+			fmt.Sprintf("Unable to get node %s after start, got error: %s", node.Label, err),
+		)
+		return *node
+	}
+	return started
+}
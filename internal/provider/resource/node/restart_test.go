@@ -0,0 +1,68 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+)
+
+func testRestartNode(ram int64) *cmlschema.NodeResourceModel {
+	return &cmlschema.NodeResourceModel{
+		NodeModel: cmlschema.NodeModel{
+			Label:           types.StringValue("r1"),
+			State:           types.StringValue("BOOTED"),
+			Configuration:   cmlschema.NewConfigValue("hostname r1"),
+			Configurations:  types.ListNull(cmlschema.NamedConfigAttrType),
+			ImageDefinition: types.StringNull(),
+			RAM:             types.Int64Value(ram),
+			CPUs:            types.Int64Null(),
+			CPUlimit:        types.Int64Null(),
+			DataVolume:      types.Int64Null(),
+			BootDiskSize:    types.Int64Null(),
+		},
+		RestartOnChange: types.StringNull(),
+	}
+}
+
+func TestHardwareChanges(t *testing.T) {
+	state := testRestartNode(512)
+
+	plan := testRestartNode(512)
+	assert.Empty(t, hardwareChanges(plan, plan, state))
+
+	plan = testRestartNode(1024)
+	plan.Configuration = cmlschema.NewConfigValue("hostname r2")
+	assert.Equal(t, []path.Path{path.Root("configuration"), path.Root("ram")}, hardwareChanges(plan, plan, state))
+
+	// attributes not in the configuration are not changed
+	config := testRestartNode(512)
+	config.RAM = types.Int64Null()
+	assert.Empty(t, hardwareChanges(config, testRestartNode(512), state))
+}
+
+func TestPlanRestart(t *testing.T) {
+	changes := []path.Path{path.Root("ram")}
+
+	var diags diag.Diagnostics
+	plan := testRestartNode(1024)
+	assert.Equal(t, changes, planRestart(plan, changes, &diags))
+	assert.False(t, diags.HasError())
+	assert.Empty(t, planRestart(plan, nil, &diags))
+
+	plan.RestartOnChange = types.StringValue(cmlschema.RestartOnChangeNever)
+	assert.Empty(t, planRestart(plan, changes, &diags))
+	assert.True(t, diags.HasError())
+
+	diags = nil
+	plan.RestartOnChange = types.StringValue(cmlschema.RestartOnChangeStopWipeRestart)
+	assert.Empty(t, planRestart(plan, changes, &diags))
+	assert.False(t, diags.HasError())
+	assert.Equal(t, 1, diags.WarningsCount())
+	assert.True(t, plan.State.IsUnknown())
+	assert.True(t, plan.Interfaces.IsUnknown())
+}
@@ -0,0 +1,175 @@
+package node
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/rschmied/gocmlclient/pkg/models"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+)
+
+// probeInterfaces is the number of placeholder interfaces used to find out
+// whether a template depends on the interfaces of a node which doesn't exist
+// yet.
+const probeInterfaces = 32
+
+// templateVars returns the config_vars of the node model.
+func templateVars(ctx context.Context, data cmlschema.NodeResourceModel, diags *diag.Diagnostics) map[string]string {
+	vars := map[string]string{}
+	if !data.ConfigVars.IsNull() && !data.ConfigVars.IsUnknown() {
+		diags.Append(data.ConfigVars.ElementsAs(ctx, &vars, false)...)
+	}
+	return vars
+}
+
+// templateKnown reports whether all values used to render the template of
+// the node model are known at plan time.
+func templateKnown(data cmlschema.NodeResourceModel) bool {
+	return !data.ConfigTemplate.IsUnknown() &&
+		!data.ConfigVars.IsUnknown() &&
+		!data.Label.IsUnknown() &&
+		!data.NodeDefinition.IsUnknown() &&
+		!data.Tags.IsUnknown() &&
+		!data.LabID.IsUnknown()
+}
+
+// renderPlanned renders the template at plan time. When the node doesn't
+// exist yet, its ID and interfaces are not known: the template is rendered
+// with two different sets of placeholders and the result is only known when
+// both renderings are identical. An error is returned only when both
+// renderings fail.
+func renderPlanned(text string, data common.ConfigTemplateData, exists bool) (string, bool, error) {
+	if exists {
+		result, err := common.RenderConfig("config_template", text, data)
+		return result, err == nil, err
+	}
+
+	probe := func(id, prefix string) (string, error) {
+		data.ID = id
+		data.Interfaces = make([]string, probeInterfaces)
+		for idx := range data.Interfaces {
+			data.Interfaces[idx] = fmt.Sprintf("%s%d", prefix, idx)
+		}
+		return common.RenderConfig("config_template", text, data)
+	}
+	first, err1 := probe("00000000-0000-0000-0000-000000000000", "probe-a-")
+	second, err2 := probe("ffffffff-ffff-ffff-ffff-ffffffffffff", "probe-b-")
+	switch {
+	case err1 != nil && err2 != nil:
+		return "", false, err1
+	case err1 != nil || err2 != nil || first != second:
+		return "", false, nil
+	}
+	return first, true, nil
+}
+
+// templateInputsEqual reports whether the values used to render the
+// template of the planned node are the same as in the state.
+func templateInputsEqual(planData, stateData *cmlschema.NodeResourceModel) bool {
+	return planData.ConfigTemplate.Equal(stateData.ConfigTemplate) &&
+		planData.ConfigVars.Equal(stateData.ConfigVars) &&
+		planData.Label.Equal(stateData.Label) &&
+		planData.NodeDefinition.Equal(stateData.NodeDefinition) &&
+		planData.Tags.Equal(stateData.Tags) &&
+		planData.LabID.Equal(stateData.LabID)
+}
+
+// planConfigTemplate sets the planned configuration of a node with a
+// config_template to the rendered template. If the template can't be
+// rendered at plan time, the configuration of an existing node is kept when
+// the inputs of the template are unchanged, otherwise it is unknown.
+func (r *NodeResource) planConfigTemplate(ctx context.Context, diags *diag.Diagnostics, planData, stateData *cmlschema.NodeResourceModel, exists bool) {
+	if planData.ConfigTemplate.IsNull() {
+		return
+	}
+	if exists && templateInputsEqual(planData, stateData) {
+		planData.Configuration = stateData.Configuration
+	} else {
+		planData.Configuration = cmlschema.NewConfigUnknown()
+	}
+	if !templateKnown(*planData) {
+		return
+	}
+
+	lab, err := r.cfg.Client().Lab.GetByID(ctx, models.UUID(planData.LabID.ValueString()), false)
+	if err != nil {
+		tflog.Info(ctx, "config template: lab not available, rendering at apply time", map[string]any{"error": err.Error()})
+		return
+	}
+
+	tags := []string{}
+	diags.Append(planData.Tags.ElementsAs(ctx, &tags, false)...)
+	data := common.NewConfigTemplateData(planData.Label.ValueString(), planData.NodeDefinition.ValueString(), tags)
+	data.LabID = string(lab.ID)
+	data.LabTitle = lab.Title
+	data.Vars = templateVars(ctx, *planData, diags)
+	if exists {
+		data.ID = stateData.ID.ValueString()
+		var ifaces []cmlschema.InterfaceModel
+		diags.Append(stateData.Interfaces.ElementsAs(ctx, &ifaces, false)...)
+		for _, iface := range ifaces {
+			data.Interfaces = append(data.Interfaces, iface.Label.ValueString())
+		}
+	}
+	if diags.HasError() {
+		return
+	}
+
+	result, known, err := renderPlanned(planData.ConfigTemplate.ValueString(), data, exists)
+	if err != nil {
+		diags.AddAttributeError(path.Root("config_template"), common.ErrorLabel, fmt.Sprintf("Unable to render configuration template: %s", err))
+		return
+	}
+	if known {
+		planData.Configuration = cmlschema.NewConfigValue(result)
+	}
+}
+
+// renderConfigTemplate renders the config_template with the values of the
+// existing node, taken from the lab. The result is false on error.
+func (r *NodeResource) renderConfigTemplate(ctx context.Context, diags *diag.Diagnostics, data cmlschema.NodeResourceModel, node *models.Node) (string, bool) {
+	lab, err := r.cfg.Client().Lab.GetByID(ctx, node.LabID, true)
+	if err != nil {
+		diags.AddError(
+			common.ErrorLabel,
+			fmt.Sprintf("Unable to fetch lab for configuration template, got error: %s", err),
+		)
+		return "", false
+	}
+	current, ok := lab.Nodes[node.ID]
+	if !ok {
+		current = node
+	}
+
+	td := common.NodeTemplateData(current, &lab)
+	td.Vars = templateVars(ctx, data, diags)
+	result, err := common.RenderConfig("config_template", data.ConfigTemplate.ValueString(), td)
+	if err != nil {
+		diags.AddAttributeError(path.Root("config_template"), common.ErrorLabel, fmt.Sprintf("Unable to render configuration template: %s", err))
+		return "", false
+	}
+	return result, !diags.HasError()
+}
+
+// applyConfigTemplate renders the config_template of a created node and sets
+// the result as the node configuration.
+func (r *NodeResource) applyConfigTemplate(ctx context.Context, diags *diag.Diagnostics, data cmlschema.NodeResourceModel, node *models.Node) {
+	result, ok := r.renderConfigTemplate(ctx, diags, data, node)
+	if !ok {
+		return
+	}
+	if err := r.cfg.Client().Node.SetConfig(ctx, node, result); err != nil {
+		diags.AddError(
+			common.ErrorLabel,
+			fmt.Sprintf("Unable to set rendered configuration, got error: %s", err),
+		)
+		return
+	}
+	node.Configuration = result
+	node.Configurations = nil
+}
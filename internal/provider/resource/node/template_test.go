@@ -0,0 +1,69 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+)
+
+func TestRenderPlanned(t *testing.T) {
+	data := common.NewConfigTemplateData("R1", "iosv", nil)
+	data.Vars["domain"] = "example.com"
+
+	// independent of ID and interfaces, known at plan time
+	result, known, err := renderPlanned("hostname {{ .Hostname }}.{{ .Vars.domain }}", data, false)
+	require.NoError(t, err)
+	assert.True(t, known)
+	assert.Equal(t, "hostname R1.example.com", result)
+
+	// depends on the interfaces of the node to be created
+	_, known, err = renderPlanned("interface {{ index .Interfaces 0 }}", data, false)
+	require.NoError(t, err)
+	assert.False(t, known)
+
+	// depends on the ID of the node to be created
+	_, known, err = renderPlanned("! {{ .ID }}", data, false)
+	require.NoError(t, err)
+	assert.False(t, known)
+
+	// existing node
+	data.ID = "n1"
+	data.Interfaces = []string{"Gi0/0"}
+	result, known, err = renderPlanned("interface {{ index .Interfaces 0 }} ! {{ .ID }}", data, true)
+	require.NoError(t, err)
+	assert.True(t, known)
+	assert.Equal(t, "interface Gi0/0 ! n1", result)
+
+	// errors
+	_, _, err = renderPlanned("{{ .Vars.missing }}", data, false)
+	assert.Error(t, err)
+	_, _, err = renderPlanned("{{ .Label ", data, true)
+	assert.Error(t, err)
+}
+
+func TestTemplateInputsEqual(t *testing.T) {
+	state := cmlschema.NodeResourceModel{
+		NodeModel: cmlschema.NodeModel{
+			Label:          types.StringValue("r1"),
+			NodeDefinition: types.StringValue("iosv"),
+			Tags:           types.SetNull(types.StringType),
+			LabID:          types.StringValue("lab"),
+		},
+		ConfigTemplate: types.StringValue("hostname {{ .Label }}"),
+		ConfigVars:     types.MapNull(types.StringType),
+	}
+	plan := state
+	assert.True(t, templateInputsEqual(&plan, &state))
+
+	plan.Label = types.StringValue("r2")
+	assert.False(t, templateInputsEqual(&plan, &state))
+
+	plan = state
+	plan.ConfigVars = types.MapUnknown(types.StringType)
+	assert.False(t, templateInputsEqual(&plan, &state))
+}
@@ -17,8 +17,8 @@ import (
 // Update updates an existing node in a CML lab.
 func (r *NodeResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	var (
-		stateData, planData cmlschema.NodeModel
-		err                 error
+		configData, stateData, planData cmlschema.NodeResourceModel
+		err                             error
 	)
 
 	tflog.Info(ctx, "Resource Node UPDATE")
@@ -30,6 +30,7 @@ func (r *NodeResource) Update(ctx context.Context, req resource.UpdateRequest, r
 	plannedGeneration := planData.Generation
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &stateData)...)
+	resp.Diagnostics.Append(req.Config.Get(ctx, &configData)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -71,8 +72,29 @@ func (r *NodeResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		node.Tags = tags
 	}
 
+	// with stop_wipe_restart, a started node is wiped to apply changes which
+	// require DEFINED_ON_CORE and started again afterwards if it was running
+	previous := models.NodeState(stateData.State.ValueString())
+	restart := previous != models.NodeStateDefined &&
+		restartPolicy(&planData) == cmlschema.RestartOnChangeStopWipeRestart &&
+		len(hardwareChanges(&configData, &planData, &stateData)) > 0
+	if restart {
+		r.stopAndWipe(ctx, &resp.Diagnostics, node, previous)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		node.State = models.NodeStateDefined
+	}
+
 	// these can only be changed when the node is DEFINED_ON_CORE
-	if stateData.State.ValueString() == string(models.NodeStateDefined) {
+	if restart || previous == models.NodeStateDefined {
+		if !planData.ConfigTemplate.IsNull() && planData.Configuration.IsUnknown() {
+			rendered, ok := r.renderConfigTemplate(ctx, &resp.Diagnostics, planData, node)
+			if !ok {
+				return
+			}
+			planData.Configuration = cmlschema.NewConfigValue(rendered)
+		}
 		if !planData.Configuration.IsUnknown() && !planData.Configuration.IsNull() && (planData.Configurations.IsNull() || planData.Configurations.IsUnknown()) {
 			cfgVal := planData.Configuration.ValueString()
 			if node.NodeDefinition == "external_connector" {
@@ -136,6 +158,13 @@ func (r *NodeResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		return
 	}
 
+	if restart && nodeIsRunning(previous) {
+		newNode = r.restart(ctx, &resp.Diagnostics, &newNode)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	// When named configs are disabled provider-side, normalize any server-returned
 	// named configs back into the single configuration field to avoid state drift.
 	if !r.cfg.UseNamedConfigs() && len(newNode.Configurations) > 0 {
@@ -174,12 +203,14 @@ func (r *NodeResource) Update(ctx context.Context, req resource.UpdateRequest, r
 			ctx,
 			cmlschema.NewNode(ctx, &newNode, &resp.Diagnostics),
 			types.ObjectType{AttrTypes: cmlschema.NodeAttrType},
-			&planData,
+			&planData.NodeModel,
 		)...,
 	)
 
 	planData.Generation = plannedGeneration
 
+	r.waitForAddresses(ctx, &resp.Diagnostics, &planData, newNode.State)
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &planData)...)
 
 	tflog.Info(ctx, "Resource Node UPDATE done")
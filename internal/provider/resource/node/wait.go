@@ -0,0 +1,31 @@
+package node
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/rschmied/gocmlclient/pkg/models"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+)
+
+// nodeIsRunning reports whether the node has been started and not stopped
+// since.
+func nodeIsRunning(state models.NodeState) bool {
+	return state == models.NodeStateStarted || state == models.NodeStateBooted
+}
+
+// waitForAddresses runs the wait_for_addresses readiness check of the node,
+// if configured and if the node is running.
+func (r *NodeResource) waitForAddresses(ctx context.Context, diags *diag.Diagnostics, data *cmlschema.NodeResourceModel, state models.NodeState) {
+	if data.WaitForAddresses.IsNull() || data.WaitForAddresses.IsUnknown() {
+		return
+	}
+	if !nodeIsRunning(state) {
+		tflog.Info(ctx, "node not running, not waiting for addresses", map[string]any{"state": state})
+		return
+	}
+	common.WaitForNodeAddresses(ctx, r.cfg.Client(), diags, data.LabID.ValueString(), data.Label.ValueString(), data.WaitForAddresses)
+}
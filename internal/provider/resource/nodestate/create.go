@@ -0,0 +1,35 @@
+package nodestate
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+)
+
+// Create brings the node into the desired state.
+func (r *NodeStateResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data cmlschema.NodeStateModel
+
+	tflog.Info(ctx, "Resource NodeState CREATE")
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout := getTimeout(ctx, &resp.Diagnostics, &data, "create")
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	r.apply(ctx, &resp.Diagnostics, &data, timeout)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	tflog.Info(ctx, "Resource NodeState CREATE done")
+}
@@ -0,0 +1,48 @@
+package nodestate
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+)
+
+// Delete applies the on_destroy policy, the node itself is not removed.
+func (r *NodeStateResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data cmlschema.NodeStateModel
+
+	tflog.Info(ctx, "Resource NodeState DELETE")
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	desired := destroyState(&data)
+	if desired == "" {
+		tflog.Info(ctx, "Resource NodeState DELETE: removing from state only")
+		return
+	}
+
+	// the node (or the lab) might have been removed already
+	_, err := r.getNode(ctx, &resp.Diagnostics, &data)
+	if err != nil {
+		if common.IsNotFound(err) {
+			tflog.Info(ctx, "Resource NodeState DELETE: node is gone")
+		}
+		return
+	}
+
+	timeout := getTimeout(ctx, &resp.Diagnostics, &data, "delete")
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.State = types.StringValue(desired)
+	r.apply(ctx, &resp.Diagnostics, &data, timeout)
+
+	tflog.Info(ctx, "Resource NodeState DELETE done")
+}
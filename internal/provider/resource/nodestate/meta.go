@@ -0,0 +1,57 @@
+// Package nodestate implements the CML2 node state resource.
+package nodestate
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ resource.Resource                = &NodeStateResource{}
+	_ resource.ResourceWithImportState = &NodeStateResource{}
+)
+
+// NodeStateResource implements the cml2_node_state resource.
+type NodeStateResource struct {
+	cfg *common.ProviderConfig
+}
+
+// NewResource returns a new node state resource.
+func NewResource() resource.Resource {
+	return &NodeStateResource{}
+}
+
+// Configure stores provider configuration for the resource.
+func (r *NodeStateResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.cfg = common.ResourceConfigure(ctx, req, resp)
+}
+
+// Metadata sets the resource type name.
+func (r *NodeStateResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_node_state"
+}
+
+// Schema defines the schema for the resource.
+func (r *NodeStateResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema.MarkdownDescription = "A node state resource controls the state of a single node, e.g. to start a node which has been added to a running lab without touching the other nodes. Only the given node is started, stopped or wiped. Don't combine it with a `cml2_lifecycle` resource which controls the same node. Destroying the resource stops the node, see `on_destroy`."
+	resp.Schema.Attributes = cmlschema.NodeState()
+}
+
+// ImportState imports a node state resource.
+func (r NodeStateResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Import format: <lab_id>/<node_id>
+	parts := common.Split2(req.ID, "/")
+	if parts == nil {
+		resp.Diagnostics.AddError(common.ErrorLabel, "invalid import id, expected <lab_id>/<node_id>")
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("lab_id"), parts[0])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("node_id"), parts[1])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), parts[1])...)
+}
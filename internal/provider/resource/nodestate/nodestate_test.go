@@ -0,0 +1,96 @@
+package nodestate_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	cml "github.com/ciscodevnet/terraform-provider-cml2/internal/provider"
+	cfg "github.com/ciscodevnet/terraform-provider-cml2/internal/testing"
+)
+
+var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"cml2": providerserver.NewProtocol6WithError(cml.New("test")()),
+}
+
+func TestNodeStateResource(t *testing.T) {
+	cfg.SkipUnlessAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() {},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testNodeStateResourceConfig(cfg.Cfg, "STARTED"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cml2_node_state.test", "state", "STARTED"),
+					resource.TestCheckResourceAttr("cml2_node_state.test", "booted", "true"),
+					resource.TestCheckResourceAttrPair("cml2_node_state.test", "id", "cml2_node.one", "id"),
+					// the other node is not touched
+					resource.TestCheckResourceAttr("data.cml2_node.two", "node.state", "DEFINED_ON_CORE"),
+				),
+			},
+			{
+				Config: testNodeStateResourceConfig(cfg.Cfg, "STOPPED"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cml2_node_state.test", "state", "STOPPED"),
+					resource.TestCheckResourceAttr("cml2_node_state.test", "booted", "false"),
+				),
+			},
+			{
+				Config: testNodeStateResourceConfig(cfg.Cfg, "DEFINED_ON_CORE"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cml2_node_state.test", "state", "DEFINED_ON_CORE"),
+				),
+			},
+			{
+				ResourceName:            "cml2_node_state.test",
+				ImportState:             true,
+				ImportStateIdFunc:       testNodeStateImportID,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"wait"},
+			},
+		},
+	})
+}
+
+func testNodeStateImportID(s *terraform.State) (string, error) {
+	rs, ok := s.RootModule().Resources["cml2_node_state.test"]
+	if !ok {
+		return "", fmt.Errorf("resource not found")
+	}
+	return rs.Primary.Attributes["lab_id"] + "/" + rs.Primary.Attributes["node_id"], nil
+}
+
+func testNodeStateResourceConfig(cfg, state string) string {
+	return fmt.Sprintf(`
+%[1]s
+resource "cml2_lab" "lab" {
+	title = "acc node state resource"
+}
+resource "cml2_node" "one" {
+	lab_id         = cml2_lab.lab.id
+	label          = "acc-node-1"
+	nodedefinition = "alpine"
+}
+resource "cml2_node" "two" {
+	lab_id         = cml2_lab.lab.id
+	label          = "acc-node-2"
+	nodedefinition = "alpine"
+}
+resource "cml2_node_state" "test" {
+	lab_id  = cml2_lab.lab.id
+	node_id = cml2_node.one.id
+	state   = %[2]q
+}
+data "cml2_node" "two" {
+	lab_id     = cml2_lab.lab.id
+	id         = cml2_node.two.id
+	depends_on = [cml2_node_state.test]
+}
+`, cfg, state)
+}
@@ -0,0 +1,42 @@
+package nodestate
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/rschmied/gocmlclient/pkg/models"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+)
+
+// Read refreshes the state from the node. A node which has been changed
+// outside of Terraform shows up as a state change, a node which doesn't
+// exist anymore removes the resource from the state.
+func (r *NodeStateResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data cmlschema.NodeStateModel
+
+	tflog.Info(ctx, "Resource NodeState READ")
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	node, err := r.getNode(ctx, &resp.Diagnostics, &data)
+	if err != nil {
+		if common.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+		}
+		return
+	}
+
+	data.State = types.StringValue(observedState(data.State.ValueString(), node.State))
+	data.Booted = types.BoolValue(node.State == models.NodeStateBooted)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	tflog.Info(ctx, "Resource NodeState READ done")
+}
@@ -0,0 +1,219 @@
+package nodestate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/rschmied/gocmlclient/pkg/models"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+)
+
+// defaultTimeout is used for operations without a configured timeout.
+const defaultTimeout = "30m"
+
+// isRunning reports whether the node has been started (or queued to start)
+// and not stopped since.
+func isRunning(state models.NodeState) bool {
+	switch state {
+	case models.NodeStateQueued, models.NodeStateStarted, models.NodeStateBooted, models.NodeStateDisconnected:
+		return true
+	}
+	return false
+}
+
+// observedState maps the node state to the desired state it satisfies: a
+// started or booted node is STARTED, a node which has never been started
+// satisfies STOPPED.
+func observedState(desired string, state models.NodeState) string {
+	switch {
+	case state == models.NodeStateDisconnected:
+		return string(state)
+	case isRunning(state):
+		return string(models.LabStateStarted)
+	case state == models.NodeStateDefined && desired == string(models.LabStateStopped):
+		return string(models.LabStateStopped)
+	}
+	return string(state)
+}
+
+// reached reports whether the node has reached the desired state. With wait,
+// a started node has to be booted.
+func reached(desired string, state models.NodeState, wait bool) bool {
+	switch models.LabState(desired) {
+	case models.LabStateStarted:
+		if wait {
+			return state == models.NodeStateBooted
+		}
+		return isRunning(state)
+	case models.LabStateStopped:
+		return state == models.NodeStateStopped || state == models.NodeStateDefined
+	case models.LabStateDefined:
+		return state == models.NodeStateDefined
+	}
+	return false
+}
+
+// destroyState returns the state the node is brought into when the resource
+// is destroyed, empty if the node is left as it is.
+func destroyState(data *cmlschema.NodeStateModel) string {
+	switch data.OnDestroy.ValueString() {
+	case cmlschema.OnDestroyNone:
+		return ""
+	case cmlschema.OnDestroyWipe:
+		return string(models.LabStateDefined)
+	}
+	return string(models.LabStateStopped)
+}
+
+// getTimeout returns the configured timeout of the operation.
+func getTimeout(ctx context.Context, diags *diag.Diagnostics, data *cmlschema.NodeStateModel, operation string) string {
+	if data.Timeouts.IsNull() || data.Timeouts.IsUnknown() {
+		return defaultTimeout
+	}
+	var timeouts cmlschema.NodeStateTimeoutsModel
+	diags.Append(data.Timeouts.As(ctx, &timeouts, basetypes.ObjectAsOptions{})...)
+	var value types.String
+	switch operation {
+	case "create":
+		value = timeouts.Create
+	case "update":
+		value = timeouts.Update
+	case "delete":
+		value = timeouts.Delete
+	}
+	if value.IsNull() || value.IsUnknown() || value.ValueString() == "" {
+		return defaultTimeout
+	}
+	return value.ValueString()
+}
+
+// getNode returns the node of the resource.
+func (r *NodeStateResource) getNode(ctx context.Context, diags *diag.Diagnostics, data *cmlschema.NodeStateModel) (models.Node, error) {
+	node, err := r.cfg.Client().Node.GetByID(ctx, models.UUID(data.LabID.ValueString()), models.UUID(data.NodeID.ValueString()))
+	if err != nil && !common.IsNotFound(err) {
+		diags.AddError(
+			common.ErrorLabel,
+			fmt.Sprintf("Unable to get node, got error: %s", err),
+		)
+	}
+	return node, err
+}
+
+// waitFor polls the node until it has reached the desired state and returns
+// it.
+func (r *NodeStateResource) waitFor(ctx context.Context, diags *diag.Diagnostics, node models.Node, desired string, wait bool, timeout string) models.Node {
+	snoozeFor := 5 // seconds
+
+	endTime, err := time.ParseDuration(timeout)
+	if err != nil {
+		diags.AddError(common.ErrorLabel, fmt.Sprintf("Can't parse timeout %q: %s", timeout, err))
+		return node
+	}
+	start := time.Now()
+	ticker := time.NewTicker(time.Second * time.Duration(snoozeFor))
+	defer ticker.Stop()
+
+	for !reached(desired, node.State, wait) {
+		if time.Since(start) > endTime {
+			diags.AddError(
+				common.ErrorLabel,
+				fmt.Sprintf("ran into timeout (max %s) waiting for node %s to reach %s, state is %s", timeout, node.Label, desired, node.State),
+			)
+			return node
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			diags.AddError(common.ErrorLabel, fmt.Sprintf("waiting for node %s: %s", node.Label, ctx.Err()))
+			return node
+		}
+		current, err := r.cfg.Client().Node.GetByID(ctx, node.LabID, node.ID)
+		if err != nil {
+			diags.AddError(
+				common.ErrorLabel,
+				fmt.Sprintf("Unable to get node %s, got error: %s", node.Label, err),
+			)
+			return node
+		}
+		current.LabID = node.LabID
+		node = current
+		tflog.Info(ctx, "waiting for node state", map[string]any{
+			"node": node.Label, "desired": desired, "state": node.State, "seconds": int(time.Since(start).Seconds()),
+		})
+	}
+	return node
+}
+
+// apply starts, stops or wipes the node to reach the desired state and runs
+// the readiness checks of a started node. The model is updated with the
+// result.
+func (r *NodeStateResource) apply(ctx context.Context, diags *diag.Diagnostics, data *cmlschema.NodeStateModel, timeout string) {
+	node, err := r.getNode(ctx, diags, data)
+	if err != nil {
+		if common.IsNotFound(err) {
+			diags.AddError(common.ErrorLabel, fmt.Sprintf("Node %s not found", data.NodeID.ValueString()))
+		}
+		return
+	}
+	labID := models.UUID(data.LabID.ValueString())
+	node.LabID = labID
+	client := r.cfg.Client()
+	desired := data.State.ValueString()
+	wait := data.Wait.IsNull() || data.Wait.ValueBool()
+
+	tflog.Info(ctx, "node state", map[string]any{"node": node.Label, "desired": desired, "state": node.State})
+
+	switch models.LabState(desired) {
+	case models.LabStateStarted:
+		if !isRunning(node.State) {
+			if err = client.Node.Start(ctx, labID, node.ID); err != nil {
+				diags.AddError(common.ErrorLabel, fmt.Sprintf("Unable to start node %s, got error: %s", node.Label, err))
+				return
+			}
+		}
+	case models.LabStateStopped, models.LabStateDefined:
+		if isRunning(node.State) {
+			if err = client.Node.Stop(ctx, labID, node.ID); err != nil {
+				diags.AddError(common.ErrorLabel, fmt.Sprintf("Unable to stop node %s, got error: %s", node.Label, err))
+				return
+			}
+		}
+		if models.LabState(desired) == models.LabStateDefined && node.State != models.NodeStateDefined {
+			// the node has to be stopped before it can be wiped
+			node = r.waitFor(ctx, diags, node, string(models.LabStateStopped), false, timeout)
+			if diags.HasError() {
+				return
+			}
+			if err = client.Node.Wipe(ctx, labID, node.ID); err != nil {
+				diags.AddError(common.ErrorLabel, fmt.Sprintf("Unable to wipe node %s, got error: %s", node.Label, err))
+				return
+			}
+		}
+	}
+
+	if node, err = client.Node.GetByID(ctx, labID, node.ID); err != nil {
+		diags.AddError(common.ErrorLabel, fmt.Sprintf("Unable to get node after state change, got error: %s", err))
+		return
+	}
+	node.LabID = labID
+	if wait {
+		node = r.waitFor(ctx, diags, node, desired, true, timeout)
+		if diags.HasError() {
+			return
+		}
+	}
+
+	if isRunning(node.State) && !data.WaitForAddresses.IsNull() && !data.WaitForAddresses.IsUnknown() {
+		common.WaitForNodeAddresses(ctx, client, diags, string(labID), node.Label, data.WaitForAddresses)
+	}
+
+	data.ID = types.StringValue(string(node.ID))
+	data.Booted = types.BoolValue(node.State == models.NodeStateBooted)
+}
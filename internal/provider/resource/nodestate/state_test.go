@@ -0,0 +1,73 @@
+package nodestate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/rschmied/gocmlclient/pkg/models"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+)
+
+func TestObservedState(t *testing.T) {
+	started := string(models.LabStateStarted)
+	stopped := string(models.LabStateStopped)
+	defined := string(models.LabStateDefined)
+
+	assert.Equal(t, started, observedState(started, models.NodeStateBooted))
+	assert.Equal(t, started, observedState(stopped, models.NodeStateQueued))
+	assert.Equal(t, stopped, observedState(stopped, models.NodeStateStopped))
+	assert.Equal(t, stopped, observedState(stopped, models.NodeStateDefined))
+	assert.Equal(t, defined, observedState(defined, models.NodeStateDefined))
+	assert.Equal(t, defined, observedState(started, models.NodeStateDefined))
+	assert.Equal(t, string(models.NodeStateDisconnected), observedState(started, models.NodeStateDisconnected))
+}
+
+func TestReached(t *testing.T) {
+	started := string(models.LabStateStarted)
+	stopped := string(models.LabStateStopped)
+	defined := string(models.LabStateDefined)
+
+	assert.True(t, reached(started, models.NodeStateBooted, true))
+	assert.False(t, reached(started, models.NodeStateStarted, true))
+	assert.True(t, reached(started, models.NodeStateStarted, false))
+	assert.False(t, reached(started, models.NodeStateStopped, false))
+	assert.True(t, reached(stopped, models.NodeStateStopped, true))
+	assert.True(t, reached(stopped, models.NodeStateDefined, true))
+	assert.False(t, reached(stopped, models.NodeStateBooted, true))
+	assert.True(t, reached(defined, models.NodeStateDefined, true))
+	assert.False(t, reached(defined, models.NodeStateStopped, true))
+}
+
+func TestGetTimeout(t *testing.T) {
+	ctx := context.Background()
+	var diags diag.Diagnostics
+
+	data := cmlschema.NodeStateModel{
+		Timeouts: types.ObjectNull(cmlschema.NodeStateTimeoutsAttrType),
+	}
+	assert.Equal(t, defaultTimeout, getTimeout(ctx, &diags, &data, "create"))
+
+	data.Timeouts = types.ObjectValueMust(cmlschema.NodeStateTimeoutsAttrType, map[string]attr.Value{
+		"create": types.StringValue("1h"),
+		"update": types.StringNull(),
+		"delete": types.StringValue("5m"),
+	})
+	assert.Equal(t, "1h", getTimeout(ctx, &diags, &data, "create"))
+	assert.Equal(t, defaultTimeout, getTimeout(ctx, &diags, &data, "update"))
+	assert.Equal(t, "5m", getTimeout(ctx, &diags, &data, "delete"))
+	assert.False(t, diags.HasError())
+}
+
+func TestDestroyState(t *testing.T) {
+	data := cmlschema.NodeStateModel{OnDestroy: types.StringNull()}
+	assert.Equal(t, string(models.LabStateStopped), destroyState(&data))
+	data.OnDestroy = types.StringValue(cmlschema.OnDestroyNone)
+	assert.Equal(t, "", destroyState(&data))
+	data.OnDestroy = types.StringValue(cmlschema.OnDestroyWipe)
+	assert.Equal(t, string(models.LabStateDefined), destroyState(&data))
+}
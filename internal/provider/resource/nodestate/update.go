@@ -0,0 +1,35 @@
+package nodestate
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+)
+
+// Update brings the node into the (changed) desired state.
+func (r *NodeStateResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data cmlschema.NodeStateModel
+
+	tflog.Info(ctx, "Resource NodeState UPDATE")
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout := getTimeout(ctx, &resp.Diagnostics, &data, "update")
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	r.apply(ctx, &resp.Diagnostics, &data, timeout)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	tflog.Info(ctx, "Resource NodeState UPDATE done")
+}
@@ -0,0 +1,182 @@
+package topology
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+	topo "github.com/ciscodevnet/terraform-provider-cml2/internal/topology"
+)
+
+// endpoint is a resolved link endpoint.
+type endpoint struct {
+	node string
+	slot int
+}
+
+func (e endpoint) String() string {
+	return fmt.Sprintf("%s:%d", e.node, e.slot)
+}
+
+// topologyData holds the nodes and links of the topology model.
+type topologyData struct {
+	nodes map[string]cmlschema.TopologyNodeModel
+	links []cmlschema.TopologyLinkModel
+}
+
+func getTopologyData(ctx context.Context, data *cmlschema.TopologyModel, diags *diag.Diagnostics) topologyData {
+	result := topologyData{nodes: map[string]cmlschema.TopologyNodeModel{}}
+	if !data.Nodes.IsNull() && !data.Nodes.IsUnknown() {
+		diags.Append(data.Nodes.ElementsAs(ctx, &result.nodes, false)...)
+	}
+	if !data.Links.IsNull() && !data.Links.IsUnknown() {
+		diags.Append(data.Links.ElementsAs(ctx, &result.links, false)...)
+	}
+	return result
+}
+
+// resolveEndpoint returns the endpoint of a link.
+func resolveEndpoint(nodes map[string]cmlschema.TopologyNodeModel, label string, slot types.Int64) (endpoint, error) {
+	if _, ok := nodes[label]; !ok {
+		return endpoint{}, fmt.Errorf("node %q of link not found in nodes", label)
+	}
+	return endpoint{node: label, slot: int(slot.ValueInt64())}, nil
+}
+
+// resolveLinks returns the endpoints of all links. Each slot of a node can
+// only be used by one link.
+func resolveLinks(data topologyData) ([][2]endpoint, error) {
+	result := [][2]endpoint{}
+	used := map[endpoint]bool{}
+	for _, link := range data.links {
+		var ends [2]endpoint
+		for idx, side := range []struct {
+			node types.String
+			slot types.Int64
+		}{
+			{link.NodeA, link.SlotA},
+			{link.NodeB, link.SlotB},
+		} {
+			ep, err := resolveEndpoint(data.nodes, side.node.ValueString(), side.slot)
+			if err != nil {
+				return nil, err
+			}
+			if used[ep] {
+				return nil, fmt.Errorf("interface slot %s is used by more than one link", ep)
+			}
+			used[ep] = true
+			ends[idx] = ep
+		}
+		result = append(result, ends)
+	}
+	return result, nil
+}
+
+// buildTopology converts the nodes and links into a topology which can be
+// compared with topology.Compare. Interfaces are created with links and are
+// not removed with them, the interface slots of the nodes in prior (if
+// given) are kept so that removing a link doesn't replace the node.
+func buildTopology(data topologyData, links [][2]endpoint, prior *topo.Topology) *topo.Topology {
+	slots := map[string]map[int]bool{}
+	for label := range data.nodes {
+		slots[label] = map[int]bool{}
+	}
+	result := &topo.Topology{}
+	for idx, ends := range links {
+		for _, ep := range ends {
+			slots[ep.node][ep.slot] = true
+		}
+		result.Links = append(result.Links, topo.Link{
+			ID: fmt.Sprintf("l%d", idx),
+			N1: ends[0].node, I1: ends[0].String(),
+			N2: ends[1].node, I2: ends[1].String(),
+		})
+	}
+
+	labels := make([]string, 0, len(data.nodes))
+	for label := range data.nodes {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		model := data.nodes[label]
+		node := topo.Node{
+			ID:             label,
+			Label:          label,
+			NodeDefinition: model.NodeDefinition.ValueString(),
+			X:              int(model.X.ValueInt64()),
+			Y:              int(model.Y.ValueInt64()),
+			Tags:           []string{},
+		}
+		if !model.ImageDefinition.IsNull() {
+			node.ImageDefinition = model.ImageDefinition.ValueString()
+		}
+		if !model.Configuration.IsNull() {
+			node.Configuration = model.Configuration.ValueString()
+		}
+		for _, elem := range model.Tags.Elements() {
+			node.Tags = append(node.Tags, elem.(types.String).ValueString())
+		}
+		if !model.RAM.IsNull() {
+			ram := int(model.RAM.ValueInt64())
+			node.RAM = &ram
+		}
+		if !model.CPUs.IsNull() {
+			cpus := int(model.CPUs.ValueInt64())
+			node.CPUs = &cpus
+		}
+		if prior != nil {
+			if old := prior.NodeByLabel(label); old != nil && old.NodeDefinition == node.NodeDefinition {
+				for _, iface := range old.Interfaces {
+					slots[label][*iface.Slot] = true
+				}
+			}
+		}
+		nodeSlots := make([]int, 0, len(slots[label]))
+		for slot := range slots[label] {
+			nodeSlots = append(nodeSlots, slot)
+		}
+		sort.Ints(nodeSlots)
+		for _, slot := range nodeSlots {
+			node.Interfaces = append(node.Interfaces, topo.Interface{
+				ID:   endpoint{node: label, slot: slot}.String(),
+				Slot: &slot,
+				Type: "physical",
+			})
+		}
+		result.Nodes = append(result.Nodes, node)
+	}
+	return result
+}
+
+// resolve returns the nodes and links of the model with the link endpoints
+// resolved. The result is false on error, errors are reported as attribute
+// errors.
+func resolve(ctx context.Context, diags *diag.Diagnostics, data *cmlschema.TopologyModel) (topologyData, [][2]endpoint, bool) {
+	td := getTopologyData(ctx, data, diags)
+	if diags.HasError() {
+		return td, nil, false
+	}
+	links, err := resolveLinks(td)
+	if err != nil {
+		diags.AddAttributeError(path.Root("links"), common.ErrorLabel, err.Error())
+		return td, nil, false
+	}
+	return td, links, true
+}
+
+// topology returns the topology of the model, prior is passed to
+// buildTopology. The result is nil on error.
+func topology(ctx context.Context, diags *diag.Diagnostics, data *cmlschema.TopologyModel, prior *topo.Topology) *topo.Topology {
+	td, links, ok := resolve(ctx, diags, data)
+	if !ok {
+		return nil
+	}
+	return buildTopology(td, links, prior)
+}
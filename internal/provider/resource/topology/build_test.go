@@ -0,0 +1,93 @@
+package topology
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	topo "github.com/ciscodevnet/terraform-provider-cml2/internal/topology"
+)
+
+func testNode(nodedef string) cmlschema.TopologyNodeModel {
+	return cmlschema.TopologyNodeModel{
+		NodeDefinition:  types.StringValue(nodedef),
+		ImageDefinition: types.StringNull(),
+		Configuration:   types.StringNull(),
+		Tags:            types.ListValueMust(types.StringType, []attr.Value{}),
+		X:               types.Int64Value(0),
+		Y:               types.Int64Value(0),
+		RAM:             types.Int64Null(),
+		CPUs:            types.Int64Null(),
+	}
+}
+
+func testLink(nodeA string, slotA int64, nodeB string, slotB int64) cmlschema.TopologyLinkModel {
+	return cmlschema.TopologyLinkModel{
+		NodeA: types.StringValue(nodeA),
+		SlotA: types.Int64Value(slotA),
+		NodeB: types.StringValue(nodeB),
+		SlotB: types.Int64Value(slotB),
+	}
+}
+
+func TestResolveLinks(t *testing.T) {
+	data := topologyData{
+		nodes: map[string]cmlschema.TopologyNodeModel{"r1": testNode("alpine"), "r2": testNode("alpine")},
+		links: []cmlschema.TopologyLinkModel{testLink("r1", 0, "r2", 1)},
+	}
+	links, err := resolveLinks(data)
+	require.NoError(t, err)
+	assert.Equal(t, [][2]endpoint{{{"r1", 0}, {"r2", 1}}}, links)
+
+	data.links = append(data.links, testLink("r2", 1, "r1", 2))
+	_, err = resolveLinks(data)
+	assert.ErrorContains(t, err, "r2:1 is used by more than one link")
+
+	data.links = []cmlschema.TopologyLinkModel{testLink("r1", 0, "r3", 0)}
+	_, err = resolveLinks(data)
+	assert.ErrorContains(t, err, `node "r3" of link not found`)
+}
+
+func TestBuildTopology(t *testing.T) {
+	data := topologyData{
+		nodes: map[string]cmlschema.TopologyNodeModel{"r1": testNode("alpine"), "r2": testNode("alpine")},
+		links: []cmlschema.TopologyLinkModel{testLink("r1", 0, "r2", 1)},
+	}
+	links, err := resolveLinks(data)
+	require.NoError(t, err)
+	old := buildTopology(data, links, nil)
+	require.Len(t, old.Nodes, 2)
+	assert.Len(t, old.Nodes[0].Interfaces, 1)
+
+	// adding a link and a node doesn't touch the existing nodes
+	data.nodes["r3"] = testNode("alpine")
+	data.links = append(data.links, testLink("r1", 1, "r3", 0))
+	links, err = resolveLinks(data)
+	require.NoError(t, err)
+	diff := topo.Compare(old, buildTopology(data, links, old), nil)
+	assert.True(t, diff.Supported())
+	assert.Len(t, diff.AddNodes, 1)
+	assert.Empty(t, diff.RemoveNodes)
+	assert.Len(t, diff.AddLinks, 1)
+
+	// removing a link keeps the interfaces of the nodes
+	data.links = data.links[:1]
+	links, err = resolveLinks(data)
+	require.NoError(t, err)
+	prior := buildTopology(data, [][2]endpoint{{{"r1", 0}, {"r2", 1}}, {{"r1", 1}, {"r3", 0}}}, nil)
+	diff = topo.Compare(prior, buildTopology(data, links, prior), nil)
+	assert.Empty(t, diff.RemoveNodes)
+	assert.Empty(t, diff.AddNodes)
+	assert.Len(t, diff.RemoveLinks, 1)
+
+	// changing the node definition replaces the node and its links
+	data.nodes["r2"] = testNode("iosv")
+	diff = topo.Compare(old, buildTopology(data, links, old), nil)
+	require.Len(t, diff.RemoveNodes, 1)
+	assert.Equal(t, "r2", diff.RemoveNodes[0].Label)
+	assert.Len(t, diff.AddLinks, 1)
+}
@@ -0,0 +1,72 @@
+package topology
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/rschmied/gocmlclient/pkg/models"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+	topo "github.com/ciscodevnet/terraform-provider-cml2/internal/topology"
+)
+
+// Create creates the nodes and links of the topology.
+func (r *TopologyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data cmlschema.TopologyModel
+
+	tflog.Info(ctx, "Resource Topology CREATE")
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	newTopo := topology(ctx, &resp.Diagnostics, &data, nil)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	lab, err := r.cfg.Client().Lab.GetByID(ctx, models.UUID(data.LabID.ValueString()), true)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			common.ErrorLabel,
+			fmt.Sprintf("Unable to get lab, got error: %s", err),
+		)
+		return
+	}
+
+	// nodes are identified by label, existing nodes of the lab are not
+	// taken over
+	existing := nodesByLabel(&lab)
+	for _, node := range newTopo.Nodes {
+		if _, ok := existing[node.Label]; ok {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("nodes").AtMapKey(node.Label),
+				common.ErrorLabel,
+				fmt.Sprintf("A node with label %q already exists in the lab", node.Label),
+			)
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diff := topo.Compare(&topo.Topology{}, newTopo, nil)
+	data.ID = types.StringValue(data.LabID.ValueString())
+	byLabel := common.ApplyTopologyDiff(ctx, r.cfg, &resp.Diagnostics, &lab, newTopo, diff, convergeTimeout)
+	if resp.Diagnostics.HasError() {
+		// record the created nodes and links, the resource is tainted
+		r.recordReached(ctx, &resp.Diagnostics, &resp.State, &cmlschema.TopologyModel{}, &data)
+		return
+	}
+
+	data.NodeIDs = newNodeIDs(&data, byLabel)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	tflog.Info(ctx, "Resource Topology CREATE done")
+}
@@ -0,0 +1,55 @@
+package topology
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/rschmied/gocmlclient/pkg/models"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+	topo "github.com/ciscodevnet/terraform-provider-cml2/internal/topology"
+)
+
+// Delete stops and removes the nodes of the topology, their links are
+// removed with them.
+func (r *TopologyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data cmlschema.TopologyModel
+
+	tflog.Info(ctx, "Resource Topology DELETE")
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	oldTopo := topology(ctx, &resp.Diagnostics, &data, nil)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	lab, err := r.cfg.Client().Lab.GetByID(ctx, models.UUID(data.LabID.ValueString()), true)
+	if err != nil {
+		if common.IsNotFound(err) {
+			// Lab already deleted, nodes and links are gone with it.
+			return
+		}
+		resp.Diagnostics.AddError(
+			common.ErrorLabel,
+			fmt.Sprintf("Unable to get lab, got error: %s", err),
+		)
+		return
+	}
+
+	diff := topo.Compare(oldTopo, &topo.Topology{}, nil)
+	common.ApplyTopologyDiff(ctx, r.cfg, &resp.Diagnostics, &lab, &topo.Topology{}, diff, convergeTimeout)
+	if resp.Diagnostics.HasError() {
+		// keep the nodes and links which are not removed yet
+		r.recordReached(ctx, &resp.Diagnostics, &resp.State, &data, &cmlschema.TopologyModel{ID: data.ID, LabID: data.LabID})
+		return
+	}
+
+	tflog.Info(ctx, "Resource Topology DELETE done")
+}
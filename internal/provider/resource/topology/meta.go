@@ -0,0 +1,46 @@
+// Package topology implements the CML2 topology resource.
+package topology
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+)
+
+// convergeTimeout is the time to wait for removed nodes to stop.
+const convergeTimeout = "30m"
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var (
+	_ resource.Resource               = &TopologyResource{}
+	_ resource.ResourceWithModifyPlan = &TopologyResource{}
+)
+
+// TopologyResource implements the cml2_topology resource.
+type TopologyResource struct {
+	cfg *common.ProviderConfig
+}
+
+// NewResource returns a new topology resource.
+func NewResource() resource.Resource {
+	return &TopologyResource{}
+}
+
+// Configure stores provider configuration for the resource.
+func (r *TopologyResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.cfg = common.ResourceConfigure(ctx, req, resp)
+}
+
+// Metadata sets the resource type name.
+func (r *TopologyResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_topology"
+}
+
+// Schema defines the schema for the resource.
+func (r *TopologyResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema.Description = "A topology resource manages a set of nodes and the links between them in an existing lab as a whole. Nodes are identified by their label and links by their endpoints, changes are computed and applied in one batch. This is an alternative to many individual node and link resources, e.g. when the topology is generated from data. Other nodes of the lab are not touched."
+	resp.Schema.Attributes = cmlschema.Topology()
+}
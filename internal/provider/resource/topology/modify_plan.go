@@ -0,0 +1,78 @@
+package topology
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/rschmied/gocmlclient/pkg/models"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	topo "github.com/ciscodevnet/terraform-provider-cml2/internal/topology"
+)
+
+// fullyKnown reports whether the value and all nested values are known.
+func fullyKnown(ctx context.Context, value attr.Value) bool {
+	tfValue, err := value.ToTerraformValue(ctx)
+	return err == nil && tfValue.IsFullyKnown()
+}
+
+// ModifyPlan validates the links of the topology and warns about nodes which
+// are replaced by the change.
+func (r *TopologyResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	var planData, stateData cmlschema.TopologyModel
+
+	if req.Plan.Raw.IsNull() || r.cfg == nil {
+		return
+	}
+
+	tflog.Info(ctx, "Resource Topology MODIFYPLAN")
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &planData)...)
+	if resp.Diagnostics.HasError() || !fullyKnown(ctx, planData.Nodes) || !fullyKnown(ctx, planData.Links) {
+		return
+	}
+
+	if req.State.Raw.IsNull() {
+		topology(ctx, &resp.Diagnostics, &planData, nil)
+		return
+	}
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &stateData)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	oldTopo := topology(ctx, &resp.Diagnostics, &stateData, nil)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	newTopo := topology(ctx, &resp.Diagnostics, &planData, oldTopo)
+	if resp.Diagnostics.HasError() || planData.LabID.ValueString() != stateData.LabID.ValueString() {
+		return
+	}
+
+	lab, err := r.cfg.Client().Lab.GetByID(ctx, models.UUID(planData.LabID.ValueString()), true)
+	if err != nil {
+		tflog.Info(ctx, "topology: lab not available, skipping replace check", map[string]any{"error": err.Error()})
+		return
+	}
+
+	diff := topo.Compare(oldTopo, newTopo, wipedFunc(&lab))
+	replaced := []string{}
+	for _, node := range diff.RemoveNodes {
+		if newTopo.NodeByLabel(node.Label) != nil {
+			replaced = append(replaced, node.Label)
+		}
+	}
+	if len(replaced) > 0 {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("nodes"),
+			"Nodes will be replaced",
+			fmt.Sprintf("The nodes %s are removed and created again, changing the node or image definition or the configuration or hardware of a node which isn't wiped replaces the node.", strings.Join(replaced, ", ")),
+		)
+	}
+}
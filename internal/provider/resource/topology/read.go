@@ -0,0 +1,184 @@
+package topology
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/rschmied/gocmlclient/pkg/models"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+)
+
+// nodesByLabel returns the nodes of the lab by label.
+func nodesByLabel(lab *models.Lab) map[string]*models.Node {
+	result := map[string]*models.Node{}
+	for _, node := range lab.Nodes {
+		if node != nil {
+			result[node.Label] = node
+		}
+	}
+	return result
+}
+
+// newNodeIDs returns the node IDs of the topology nodes, nodes which don't
+// exist in byLabel are omitted.
+func newNodeIDs(data *cmlschema.TopologyModel, byLabel map[string]*models.Node) types.Map {
+	ids := map[string]attr.Value{}
+	for label := range data.Nodes.Elements() {
+		if node, ok := byLabel[label]; ok {
+			ids[label] = types.StringValue(string(node.ID))
+		}
+	}
+	return types.MapValueMust(types.StringType, ids)
+}
+
+// refresh updates the model with the nodes and links found in the lab:
+// nodes and links which don't exist anymore are removed, position and tags
+// of the nodes are taken from the lab.
+func (r *TopologyResource) refresh(ctx context.Context, diags *diag.Diagnostics, lab *models.Lab, data *cmlschema.TopologyModel) {
+	td, links, ok := resolve(ctx, diags, data)
+	if !ok {
+		return
+	}
+	byLabel := nodesByLabel(lab)
+
+	nodes := map[string]attr.Value{}
+	for label, model := range td.nodes {
+		node, ok := byLabel[label]
+		if !ok {
+			continue
+		}
+		model.NodeDefinition = types.StringValue(node.NodeDefinition)
+		model.X = types.Int64Value(int64(node.X))
+		model.Y = types.Int64Value(int64(node.Y))
+		tags, dd := types.ListValueFrom(ctx, types.StringType, node.Tags)
+		diags.Append(dd...)
+		if node.Tags == nil {
+			tags = types.ListValueMust(types.StringType, []attr.Value{})
+		}
+		model.Tags = tags
+		value, dd := types.ObjectValueFrom(ctx, cmlschema.TopologyNodeAttrType, model)
+		diags.Append(dd...)
+		nodes[label] = value
+	}
+	data.Nodes = types.MapValueMust(types.ObjectType{AttrTypes: cmlschema.TopologyNodeAttrType}, nodes)
+
+	if !data.Links.IsNull() {
+		existing := map[string]bool{}
+		for _, link := range lab.Links {
+			src, dst, ok := common.LinkEndpoints(lab, link)
+			if ok {
+				existing[src+"-"+dst] = true
+				existing[dst+"-"+src] = true
+			}
+		}
+		kept := []attr.Value{}
+		for idx, ends := range links {
+			if !existing[ends[0].String()+"-"+ends[1].String()] {
+				continue
+			}
+			value, dd := types.ObjectValueFrom(ctx, cmlschema.TopologyLinkAttrType, td.links[idx])
+			diags.Append(dd...)
+			kept = append(kept, value)
+		}
+		data.Links = types.SetValueMust(types.ObjectType{AttrTypes: cmlschema.TopologyLinkAttrType}, kept)
+	}
+	data.NodeIDs = newNodeIDs(data, byLabel)
+}
+
+// reached returns the model of the nodes and links which exist in the lab
+// after a partial failure. Nodes and links of the prior state are kept unless
+// they were removed, planned ones are added if they were created. Nodes in
+// both keep their prior values so that the next plan applies the update
+// again.
+func (r *TopologyResource) reached(ctx context.Context, diags *diag.Diagnostics, lab *models.Lab, prior, plan *cmlschema.TopologyModel) cmlschema.TopologyModel {
+	priorData := getTopologyData(ctx, prior, diags)
+	planData := getTopologyData(ctx, plan, diags)
+	result := *plan
+
+	nodes := planData.nodes
+	for label, model := range priorData.nodes {
+		nodes[label] = model
+	}
+	value, dd := types.MapValueFrom(ctx, types.ObjectType{AttrTypes: cmlschema.TopologyNodeAttrType}, nodes)
+	diags.Append(dd...)
+	result.Nodes = value
+
+	if !prior.Links.IsNull() || !plan.Links.IsNull() {
+		seen := map[string]bool{}
+		links := []cmlschema.TopologyLinkModel{}
+		for _, link := range append(priorData.links, planData.links...) {
+			a := endpoint{node: link.NodeA.ValueString(), slot: int(link.SlotA.ValueInt64())}
+			b := endpoint{node: link.NodeB.ValueString(), slot: int(link.SlotB.ValueInt64())}
+			_, okA := nodes[a.node]
+			_, okB := nodes[b.node]
+			if !okA || !okB || seen[a.String()+"-"+b.String()] {
+				continue
+			}
+			seen[a.String()+"-"+b.String()] = true
+			seen[b.String()+"-"+a.String()] = true
+			links = append(links, link)
+		}
+		value, dd := types.SetValueFrom(ctx, types.ObjectType{AttrTypes: cmlschema.TopologyLinkAttrType}, links)
+		diags.Append(dd...)
+		result.Links = value
+	}
+
+	r.refresh(ctx, diags, lab, &result)
+	return result
+}
+
+// recordReached records the nodes and links which exist in the lab after a
+// partial failure in the state, see reached.
+func (r *TopologyResource) recordReached(ctx context.Context, diags *diag.Diagnostics, state *tfsdk.State, prior, plan *cmlschema.TopologyModel) {
+	lab, err := r.cfg.Client().Lab.GetByID(ctx, models.UUID(plan.LabID.ValueString()), true)
+	if err != nil {
+		diags.AddError(
+			common.ErrorLabel,
+			fmt.Sprintf("Unable to get lab after partial failure, got error: %s", err),
+		)
+		return
+	}
+	data := r.reached(ctx, diags, &lab, prior, plan)
+	diags.Append(state.Set(ctx, &data)...)
+}
+
+// Read refreshes the topology from the lab.
+func (r *TopologyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data cmlschema.TopologyModel
+
+	tflog.Info(ctx, "Resource Topology READ")
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	lab, err := r.cfg.Client().Lab.GetByID(ctx, models.UUID(data.LabID.ValueString()), true)
+	if err != nil {
+		if common.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			common.ErrorLabel,
+			fmt.Sprintf("Unable to get lab, got error: %s", err),
+		)
+		return
+	}
+
+	r.refresh(ctx, &resp.Diagnostics, &lab, &data)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	tflog.Info(ctx, "Resource Topology READ done")
+}
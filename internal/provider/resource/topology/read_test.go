@@ -0,0 +1,63 @@
+package topology
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/rschmied/gocmlclient/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+)
+
+func testModel(t *testing.T, nodes map[string]cmlschema.TopologyNodeModel, links []cmlschema.TopologyLinkModel) *cmlschema.TopologyModel {
+	t.Helper()
+	ctx := context.Background()
+	nodeMap, diags := types.MapValueFrom(ctx, types.ObjectType{AttrTypes: cmlschema.TopologyNodeAttrType}, nodes)
+	require.False(t, diags.HasError())
+	linkSet, diags := types.SetValueFrom(ctx, types.ObjectType{AttrTypes: cmlschema.TopologyLinkAttrType}, links)
+	require.False(t, diags.HasError())
+	return &cmlschema.TopologyModel{
+		ID:      types.StringValue("lab"),
+		LabID:   types.StringValue("lab"),
+		Nodes:   nodeMap,
+		Links:   linkSet,
+		NodeIDs: types.MapUnknown(types.StringType),
+	}
+}
+
+func TestReached(t *testing.T) {
+	ctx := context.Background()
+
+	// r2 was replaced by r3, r3 was created but linking it failed
+	prior := testModel(t,
+		map[string]cmlschema.TopologyNodeModel{"r1": testNode("alpine"), "r2": testNode("alpine")},
+		[]cmlschema.TopologyLinkModel{testLink("r1", 0, "r2", 0)},
+	)
+	plan := testModel(t,
+		map[string]cmlschema.TopologyNodeModel{"r1": testNode("iosv"), "r3": testNode("alpine")},
+		[]cmlschema.TopologyLinkModel{testLink("r1", 1, "r3", 0)},
+	)
+	lab := &models.Lab{
+		Nodes: models.NodeMap{
+			"n1": {ID: "n1", Label: "r1", NodeDefinition: "alpine"},
+			"n3": {ID: "n3", Label: "r3", NodeDefinition: "alpine"},
+		},
+	}
+
+	diags := diag.Diagnostics{}
+	r := &TopologyResource{}
+	got := r.reached(ctx, &diags, lab, prior, plan)
+	require.False(t, diags.HasError(), diags)
+
+	nodes := map[string]cmlschema.TopologyNodeModel{}
+	require.False(t, got.Nodes.ElementsAs(ctx, &nodes, false).HasError())
+	assert.Len(t, nodes, 2)
+	assert.Equal(t, "alpine", nodes["r1"].NodeDefinition.ValueString())
+	assert.Contains(t, nodes, "r3")
+	assert.Empty(t, got.Links.Elements())
+	assert.Equal(t, types.StringValue("n3"), got.NodeIDs.Elements()["r3"])
+}
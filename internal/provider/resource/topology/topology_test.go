@@ -0,0 +1,77 @@
+package topology_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+
+	cml "github.com/ciscodevnet/terraform-provider-cml2/internal/provider"
+	cfg "github.com/ciscodevnet/terraform-provider-cml2/internal/testing"
+)
+
+var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"cml2": providerserver.NewProtocol6WithError(cml.New("test")()),
+}
+
+func TestAccTopologyResource(t *testing.T) {
+	cfg.SkipUnlessAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() {},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTopologyResourceConfig(cfg.Cfg, 2),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cml2_topology.topo", "nodes.%", "3"),
+					resource.TestCheckResourceAttr("cml2_topology.topo", "links.#", "2"),
+					resource.TestCheckResourceAttr("cml2_topology.topo", "node_ids.%", "3"),
+					resource.TestCheckResourceAttrPair("cml2_topology.topo", "id", "cml2_lab.test", "id"),
+				),
+			},
+			{
+				Config: testAccTopologyResourceConfig(cfg.Cfg, 4),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cml2_topology.topo", "nodes.%", "5"),
+					resource.TestCheckResourceAttr("cml2_topology.topo", "links.#", "4"),
+					resource.TestCheckResourceAttr("cml2_topology.topo", "node_ids.%", "5"),
+				),
+			},
+			{
+				Config: testAccTopologyResourceConfig(cfg.Cfg, 1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cml2_topology.topo", "nodes.%", "2"),
+					resource.TestCheckResourceAttr("cml2_topology.topo", "links.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func testAccTopologyResourceConfig(cfg string, hosts int) string {
+	return fmt.Sprintf(`
+%[1]s
+resource "cml2_lab" "test" {
+	title = "acc topology resource"
+}
+locals {
+	hosts = [for idx in range(%[2]d) : "host-${idx}"]
+}
+resource "cml2_topology" "topo" {
+	lab_id = cml2_lab.test.id
+	nodes = merge(
+		{ switch = { nodedefinition = "unmanaged_switch", x = 0, y = 0 } },
+		{ for idx, name in local.hosts : name => { nodedefinition = "alpine", x = idx * 80, y = 120, tags = ["hosts"] } },
+	)
+	links = [for idx, name in local.hosts : {
+		node_a = "switch"
+		slot_a = idx
+		node_b = name
+		slot_b = 0
+	}]
+}
+`, cfg, hosts)
+}
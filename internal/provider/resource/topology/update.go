@@ -0,0 +1,85 @@
+package topology
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/rschmied/gocmlclient/pkg/models"
+
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/cmlschema"
+	"github.com/ciscodevnet/terraform-provider-cml2/internal/common"
+	topo "github.com/ciscodevnet/terraform-provider-cml2/internal/topology"
+)
+
+// wipedFunc returns a function which reports whether the node with the given
+// label is wiped (or doesn't exist) in the lab.
+func wipedFunc(lab *models.Lab) func(label string) bool {
+	byLabel := nodesByLabel(lab)
+	return func(label string) bool {
+		node, ok := byLabel[label]
+		return !ok || node.State == models.NodeStateDefined
+	}
+}
+
+// Update applies the changes of the topology in place.
+func (r *TopologyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var planData, stateData cmlschema.TopologyModel
+
+	tflog.Info(ctx, "Resource Topology UPDATE")
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &planData)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &stateData)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	oldTopo := topology(ctx, &resp.Diagnostics, &stateData, nil)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	newTopo := topology(ctx, &resp.Diagnostics, &planData, oldTopo)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	lab, err := r.cfg.Client().Lab.GetByID(ctx, models.UUID(planData.LabID.ValueString()), true)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			common.ErrorLabel,
+			fmt.Sprintf("Unable to get lab, got error: %s", err),
+		)
+		return
+	}
+
+	diff := topo.Compare(oldTopo, newTopo, wipedFunc(&lab))
+	if !diff.Supported() {
+		resp.Diagnostics.AddError(
+			common.ErrorLabel,
+			fmt.Sprintf("Unable to apply topology changes: %s", strings.Join(diff.Unsupported, ", ")),
+		)
+		return
+	}
+	tflog.Info(ctx, "Resource Topology UPDATE: applying changes", map[string]any{
+		"add_nodes":    len(diff.AddNodes),
+		"remove_nodes": len(diff.RemoveNodes),
+		"update_nodes": len(diff.UpdateNodes),
+		"add_links":    len(diff.AddLinks),
+		"remove_links": len(diff.RemoveLinks),
+	})
+
+	byLabel := common.ApplyTopologyDiff(ctx, r.cfg, &resp.Diagnostics, &lab, newTopo, diff, convergeTimeout)
+	if resp.Diagnostics.HasError() {
+		// record what was reached, the next plan computes the remaining
+		// changes
+		r.recordReached(ctx, &resp.Diagnostics, &resp.State, &stateData, &planData)
+		return
+	}
+
+	planData.NodeIDs = newNodeIDs(&planData, byLabel)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &planData)...)
+
+	tflog.Info(ctx, "Resource Topology UPDATE done")
+}
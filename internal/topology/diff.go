@@ -0,0 +1,279 @@
+package topology
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+)
+
+// nodeKeys are the node attributes which are handled explicitly by Compare.
+// Changes to any other attribute of a node result in the node being replaced.
+var nodeKeys = []string{
+	"id", "label", "node_definition", "image_definition", "configuration",
+	"tags", "x", "y", "hide_links", "ram", "cpus", "cpu_limit", "data_volume",
+	"boot_disk_size", "interfaces",
+}
+
+// NodeUpdate describes the in-place changes of a node.
+type NodeUpdate struct {
+	Old, New *Node
+	// Position is set when x, y or hide_links changed.
+	Position bool
+	// Tags is set when the node tags changed.
+	Tags bool
+	// Configuration is set when the node configuration changed, this
+	// requires the node to be wiped.
+	Configuration bool
+	// Hardware is set when RAM, CPUs, CPU limit, data volume or boot disk
+	// size changed, this requires the node to be wiped.
+	Hardware bool
+}
+
+// LinkEnds identifies a link by its label and endpoints (node label and
+// interface slot). NodeA/SlotA is always the lower endpoint.
+type LinkEnds struct {
+	Label string
+	NodeA string
+	SlotA int
+	NodeB string
+	SlotB int
+}
+
+func newLinkEnds(label, nodeA string, slotA int, nodeB string, slotB int) LinkEnds {
+	if nodeB < nodeA || (nodeA == nodeB && slotB < slotA) {
+		nodeA, slotA, nodeB, slotB = nodeB, slotB, nodeA, slotA
+	}
+	return LinkEnds{Label: label, NodeA: nodeA, SlotA: slotA, NodeB: nodeB, SlotB: slotB}
+}
+
+func (l LinkEnds) String() string {
+	return fmt.Sprintf("%s:%d-%s:%d", l.NodeA, l.SlotA, l.NodeB, l.SlotB)
+}
+
+// Diff is the structural difference between two topologies.
+type Diff struct {
+	// Lab is set when title, description or notes changed.
+	Lab            bool
+	AddNodes       []*Node
+	RemoveNodes    []*Node
+	UpdateNodes    []NodeUpdate
+	AddLinks       []LinkEnds
+	RemoveLinks    []LinkEnds
+	AddAnnotations []map[string]any
+	// Unsupported lists the reasons why the difference can't be applied in
+	// place, the lab has to be replaced if it is not empty.
+	Unsupported []string
+}
+
+// Empty reports whether there's no difference at all.
+func (d *Diff) Empty() bool {
+	return !d.Lab && len(d.AddNodes) == 0 && len(d.RemoveNodes) == 0 &&
+		len(d.UpdateNodes) == 0 && len(d.AddLinks) == 0 && len(d.RemoveLinks) == 0 &&
+		len(d.AddAnnotations) == 0 && len(d.Unsupported) == 0
+}
+
+// Supported reports whether the difference can be applied in place.
+func (d *Diff) Supported() bool {
+	return len(d.Unsupported) == 0
+}
+
+func nodeMap(topo *Topology) map[string]*Node {
+	result := make(map[string]*Node, len(topo.Nodes))
+	for idx := range topo.Nodes {
+		result[topo.Nodes[idx].Label] = &topo.Nodes[idx]
+	}
+	return result
+}
+
+func physicalSlots(node *Node) map[int]bool {
+	slots := map[int]bool{}
+	for _, iface := range node.Interfaces {
+		if iface.Slot != nil && iface.Type != "loopback" {
+			slots[*iface.Slot] = true
+		}
+	}
+	return slots
+}
+
+func unhandledChanged(old, new *Node) bool {
+	keys := map[string]bool{}
+	for key := range old.Raw {
+		keys[key] = true
+	}
+	for key := range new.Raw {
+		keys[key] = true
+	}
+	for key := range keys {
+		if slices.Contains(nodeKeys, key) {
+			continue
+		}
+		if !reflect.DeepEqual(old.Raw[key], new.Raw[key]) {
+			return true
+		}
+	}
+	return false
+}
+
+// linkEnds returns the link ends of all links of the topology. Links with
+// endpoints which can't be resolved to a node label and slot are reported as
+// problems.
+func linkEnds(topo *Topology) ([]LinkEnds, []string) {
+	type endpoint struct {
+		label string
+		slots map[string]*int
+	}
+	nodes := map[string]endpoint{}
+	for _, node := range topo.Nodes {
+		ep := endpoint{label: node.Label, slots: map[string]*int{}}
+		for _, iface := range node.Interfaces {
+			ep.slots[iface.ID] = iface.Slot
+		}
+		nodes[node.ID] = ep
+	}
+
+	resolve := func(nodeID, ifaceID string) (string, int, bool) {
+		ep, ok := nodes[nodeID]
+		if !ok {
+			return "", 0, false
+		}
+		slot, ok := ep.slots[ifaceID]
+		if !ok || slot == nil {
+			return "", 0, false
+		}
+		return ep.label, *slot, true
+	}
+
+	result := []LinkEnds{}
+	problems := []string{}
+	for _, link := range topo.Links {
+		nodeA, slotA, okA := resolve(link.N1, link.I1)
+		nodeB, slotB, okB := resolve(link.N2, link.I2)
+		if !okA || !okB {
+			problems = append(problems, fmt.Sprintf("endpoints of link %q can't be resolved to a node interface slot", link.ID))
+			continue
+		}
+		result = append(result, newLinkEnds(link.Label, nodeA, slotA, nodeB, slotB))
+	}
+	return result, problems
+}
+
+// Compare computes the difference from the old to the new topology. Nodes
+// are identified by their label, links by their endpoints. A node is
+// replaced (removed and added again) when its node or image definition or
+// an unmodeled attribute changes, when physical interfaces are removed or
+// when its configuration or hardware changes while the node is not wiped,
+// as reported by the wiped function (nil means all nodes are wiped). Links
+// of replaced nodes are re-created.
+func Compare(old, new *Topology, wiped func(label string) bool) *Diff {
+	diff := &Diff{}
+
+	if old.Lab.Title != new.Lab.Title || old.Lab.Description != new.Lab.Description || old.Lab.Notes != new.Lab.Notes {
+		diff.Lab = true
+	}
+
+	if !reflect.DeepEqual(old.Extra, new.Extra) {
+		diff.Unsupported = append(diff.Unsupported, "top level topology sections other than lab, nodes, links and annotations changed")
+	}
+
+	// annotations have no identity, only appending annotations is supported
+	switch {
+	case len(new.Annotations) < len(old.Annotations):
+		diff.Unsupported = append(diff.Unsupported, "existing annotations removed")
+	case len(old.Annotations) > 0 && !reflect.DeepEqual(old.Annotations, new.Annotations[:len(old.Annotations)]):
+		diff.Unsupported = append(diff.Unsupported, "existing annotations changed")
+	default:
+		diff.AddAnnotations = new.Annotations[len(old.Annotations):]
+	}
+
+	oldNodes, newNodes := nodeMap(old), nodeMap(new)
+	replaced := map[string]bool{}
+
+	labels := make([]string, 0, len(oldNodes))
+	for label := range oldNodes {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		oldNode := oldNodes[label]
+		newNode, ok := newNodes[label]
+		if !ok {
+			diff.RemoveNodes = append(diff.RemoveNodes, oldNode)
+			continue
+		}
+
+		update := NodeUpdate{
+			Old:           oldNode,
+			New:           newNode,
+			Position:      oldNode.X != newNode.X || oldNode.Y != newNode.Y || !reflect.DeepEqual(oldNode.HideLinks, newNode.HideLinks),
+			Tags:          !slices.Equal(oldNode.Tags, newNode.Tags),
+			Configuration: !reflect.DeepEqual(oldNode.Configuration, newNode.Configuration),
+			Hardware: !reflect.DeepEqual(oldNode.RAM, newNode.RAM) ||
+				!reflect.DeepEqual(oldNode.CPUs, newNode.CPUs) ||
+				!reflect.DeepEqual(oldNode.CPUlimit, newNode.CPUlimit) ||
+				!reflect.DeepEqual(oldNode.DataVolume, newNode.DataVolume) ||
+				!reflect.DeepEqual(oldNode.BootDiskSize, newNode.BootDiskSize),
+		}
+
+		replace := oldNode.NodeDefinition != newNode.NodeDefinition ||
+			oldNode.ImageDefinition != newNode.ImageDefinition ||
+			unhandledChanged(oldNode, newNode)
+		newSlots := physicalSlots(newNode)
+		for slot := range physicalSlots(oldNode) {
+			if !newSlots[slot] {
+				replace = true
+			}
+		}
+		if (update.Configuration || update.Hardware) && wiped != nil && !wiped(label) {
+			replace = true
+		}
+
+		switch {
+		case replace:
+			replaced[label] = true
+			diff.RemoveNodes = append(diff.RemoveNodes, oldNode)
+			diff.AddNodes = append(diff.AddNodes, newNode)
+		case update.Position || update.Tags || update.Configuration || update.Hardware:
+			diff.UpdateNodes = append(diff.UpdateNodes, update)
+		}
+	}
+
+	labels = labels[:0]
+	for label := range newNodes {
+		if _, ok := oldNodes[label]; !ok {
+			labels = append(labels, label)
+		}
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		diff.AddNodes = append(diff.AddNodes, newNodes[label])
+	}
+
+	oldLinks, oldProblems := linkEnds(old)
+	newLinks, newProblems := linkEnds(new)
+	diff.Unsupported = append(diff.Unsupported, oldProblems...)
+	diff.Unsupported = append(diff.Unsupported, newProblems...)
+
+	touchesReplaced := func(link LinkEnds) bool {
+		return replaced[link.NodeA] || replaced[link.NodeB]
+	}
+	for _, link := range oldLinks {
+		// links of removed nodes are removed together with the node
+		if _, ok := newNodes[link.NodeA]; !ok {
+			continue
+		}
+		if _, ok := newNodes[link.NodeB]; !ok {
+			continue
+		}
+		if !slices.Contains(newLinks, link) && !touchesReplaced(link) {
+			diff.RemoveLinks = append(diff.RemoveLinks, link)
+		}
+	}
+	for _, link := range newLinks {
+		if !slices.Contains(oldLinks, link) || touchesReplaced(link) {
+			diff.AddLinks = append(diff.AddLinks, link)
+		}
+	}
+
+	return diff
+}
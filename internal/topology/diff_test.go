@@ -0,0 +1,183 @@
+package topology
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func labels(nodes []*Node) []string {
+	result := []string{}
+	for _, node := range nodes {
+		result = append(result, node.Label)
+	}
+	return result
+}
+
+func linkStrings(links []LinkEnds) []string {
+	result := []string{}
+	for _, link := range links {
+		result = append(result, link.String())
+	}
+	return result
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name        string
+		edit        func(string) string
+		wiped       func(string) bool
+		add         []string
+		remove      []string
+		update      []string
+		addLinks    []string
+		removeLinks []string
+		unsupported bool
+	}{
+		{
+			name: "no change",
+			edit: func(s string) string { return s },
+		},
+		{
+			name: "add node and link",
+			edit: func(s string) string {
+				return strings.Replace(s, "links:\n", `  - id: n2
+    label: r3
+    node_definition: iosv
+    interfaces:
+      - id: i0
+        slot: 0
+        type: physical
+links:
+  - id: l1
+    n1: n1
+    n2: n2
+    i1: i0
+    i2: i0
+`, 1) // slot 0 of r2 is already in use, Compare does not check this
+			},
+			add:      []string{"r3"},
+			addLinks: []string{"r2:0-r3:0"},
+		},
+		{
+			name: "remove node",
+			edit: func(s string) string {
+				s = strings.Replace(s, "links:\n  - id: l0\n    n1: n0\n    n2: n1\n    i1: i1\n    i2: i0\n", "", 1)
+				return s[:strings.Index(s, "  - id: n1\n")]
+			},
+			remove: []string{"r2"},
+		},
+		{
+			name: "position and tags",
+			edit: func(s string) string {
+				return strings.Replace(s, "tags: [core]", "tags: [core, edge]\n    x: 100", 1)
+			},
+			update: []string{"r1"},
+		},
+		{
+			name: "configuration of wiped node",
+			edit: func(s string) string {
+				return strings.Replace(s, "tags: [core]", "tags: [core]\n    configuration: hostname r1", 1)
+			},
+			update: []string{"r1"},
+		},
+		{
+			name: "configuration of booted node",
+			edit: func(s string) string {
+				return strings.Replace(s, "tags: [core]", "tags: [core]\n    configuration: hostname r1", 1)
+			},
+			wiped:    func(string) bool { return false },
+			add:      []string{"r1"},
+			remove:   []string{"r1"},
+			addLinks: []string{"r1:0-r2:0"},
+		},
+		{
+			name: "node definition",
+			edit: func(s string) string {
+				return strings.Replace(s, "image_definition: iosv-159-3", "image_definition: iosv-159-4", 1)
+			},
+			add:      []string{"r2"},
+			remove:   []string{"r2"},
+			addLinks: []string{"r1:0-r2:0"},
+		},
+		{
+			name: "remove link",
+			edit: func(s string) string {
+				return s[:strings.Index(s, "links:\n")]
+			},
+			removeLinks: []string{"r1:0-r2:0"},
+		},
+		{
+			name: "append annotation",
+			edit: func(s string) string {
+				return s + "annotations:\n  - type: text\n    text_content: hello\n"
+			},
+		},
+		{
+			name: "extra section",
+			edit: func(s string) string {
+				return s + "smart_annotations: []\n"
+			},
+			unsupported: true,
+		},
+		{
+			name: "unresolvable link",
+			edit: func(s string) string {
+				return strings.Replace(s, "i2: i0", "i2: i9", 1)
+			},
+			unsupported: true,
+		},
+	}
+
+	old, err := Parse(testTopology)
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topo, err := Parse(tt.edit(testTopology))
+			require.NoError(t, err)
+
+			diff := Compare(old, topo, tt.wiped)
+			assert.Equal(t, !tt.unsupported, diff.Supported(), diff.Unsupported)
+			if tt.unsupported {
+				return
+			}
+			updated := []*Node{}
+			for _, update := range diff.UpdateNodes {
+				updated = append(updated, update.New)
+			}
+			assert.Equal(t, nonNil(tt.add), labels(diff.AddNodes), "add")
+			assert.Equal(t, nonNil(tt.remove), labels(diff.RemoveNodes), "remove")
+			assert.Equal(t, nonNil(tt.update), labels(updated), "update")
+			assert.Equal(t, nonNil(tt.addLinks), linkStrings(diff.AddLinks), "add links")
+			assert.Equal(t, nonNil(tt.removeLinks), linkStrings(diff.RemoveLinks), "remove links")
+		})
+	}
+}
+
+func TestCompareAnnotations(t *testing.T) {
+	old, err := Parse(testTopology + "annotations:\n  - type: text\n    text_content: hello\n")
+	require.NoError(t, err)
+
+	topo, err := Parse(testTopology + "annotations:\n  - type: text\n    text_content: hello\n  - type: text\n    text_content: world\n")
+	require.NoError(t, err)
+	diff := Compare(old, topo, nil)
+	assert.True(t, diff.Supported())
+	assert.Len(t, diff.AddAnnotations, 1)
+	assert.False(t, diff.Empty())
+
+	topo, err = Parse(testTopology + "annotations:\n  - type: text\n    text_content: changed\n")
+	require.NoError(t, err)
+	assert.False(t, Compare(old, topo, nil).Supported())
+
+	assert.True(t, Compare(old, old, nil).Empty())
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,236 @@
+// Package topology parses CML topology YAML files (as used by the lifecycle
+// resource) into a minimal model which keeps track of the line numbers of the
+// individual elements so that problems can be reported with a location.
+package topology
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Lab holds the lab level properties of a topology.
+type Lab struct {
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	Notes       string `yaml:"notes"`
+	Version     string `yaml:"version"`
+	Line        int    `yaml:"-"`
+}
+
+// Interface is a node interface of a topology.
+type Interface struct {
+	ID    string `yaml:"id"`
+	Label string `yaml:"label"`
+	Slot  *int   `yaml:"slot"`
+	Type  string `yaml:"type"`
+	Line  int    `yaml:"-"`
+}
+
+// Node is a node of a topology.
+type Node struct {
+	ID              string      `yaml:"id"`
+	Label           string      `yaml:"label"`
+	NodeDefinition  string      `yaml:"node_definition"`
+	ImageDefinition string      `yaml:"image_definition"`
+	Configuration   any         `yaml:"configuration"`
+	Tags            []string    `yaml:"tags"`
+	X               int         `yaml:"x"`
+	Y               int         `yaml:"y"`
+	HideLinks       *bool       `yaml:"hide_links"`
+	RAM             *int        `yaml:"ram"`
+	CPUs            *int        `yaml:"cpus"`
+	CPUlimit        *int        `yaml:"cpu_limit"`
+	DataVolume      *int        `yaml:"data_volume"`
+	BootDiskSize    *int        `yaml:"boot_disk_size"`
+	Interfaces      []Interface `yaml:"interfaces"`
+	Line            int         `yaml:"-"`
+
+	// Raw holds all attributes of the node as found in the topology, used
+	// to detect changes of attributes which are not modeled above.
+	Raw map[string]any `yaml:"-"`
+}
+
+// Link is a link between two node interfaces of a topology.
+type Link struct {
+	ID    string `yaml:"id"`
+	Label string `yaml:"label"`
+	N1    string `yaml:"n1"`
+	N2    string `yaml:"n2"`
+	I1    string `yaml:"i1"`
+	I2    string `yaml:"i2"`
+	Line  int    `yaml:"-"`
+}
+
+// Topology is the parsed representation of a topology YAML.
+type Topology struct {
+	Lab         Lab
+	Nodes       []Node
+	Links       []Link
+	Annotations []map[string]any
+
+	// Extra holds all other top level sections of the topology.
+	Extra map[string]any
+}
+
+// Parse parses the given topology YAML. Errors from the YAML parser already
+// include the line number.
+func Parse(data string) (*Topology, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(data), &root); err != nil {
+		return nil, err
+	}
+	if len(root.Content) == 0 {
+		return nil, errors.New("topology is empty")
+	}
+	doc := root.Content[0]
+	if doc.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: topology must be a mapping", doc.Line)
+	}
+
+	topo := &Topology{Extra: map[string]any{}}
+	for idx := 0; idx+1 < len(doc.Content); idx += 2 {
+		key, value := doc.Content[idx], doc.Content[idx+1]
+		switch key.Value {
+		case "lab":
+			if err := value.Decode(&topo.Lab); err != nil {
+				return nil, err
+			}
+			topo.Lab.Line = key.Line
+		case "nodes":
+			if err := decodeList(value, func(item *yaml.Node) error {
+				var node Node
+				if err := item.Decode(&node); err != nil {
+					return err
+				}
+				node.Line = item.Line
+				if err := item.Decode(&node.Raw); err != nil {
+					return err
+				}
+				if ifaces := mappingValue(item, "interfaces"); ifaces != nil && ifaces.Kind == yaml.SequenceNode {
+					for i, iface := range ifaces.Content {
+						if i < len(node.Interfaces) {
+							node.Interfaces[i].Line = iface.Line
+						}
+					}
+				}
+				topo.Nodes = append(topo.Nodes, node)
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+		case "links":
+			if err := decodeList(value, func(item *yaml.Node) error {
+				var link Link
+				if err := item.Decode(&link); err != nil {
+					return err
+				}
+				link.Line = item.Line
+				topo.Links = append(topo.Links, link)
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+		case "annotations":
+			if err := decodeList(value, func(item *yaml.Node) error {
+				var annotation map[string]any
+				if err := item.Decode(&annotation); err != nil {
+					return err
+				}
+				topo.Annotations = append(topo.Annotations, annotation)
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+		default:
+			var extra any
+			if err := value.Decode(&extra); err != nil {
+				return nil, err
+			}
+			topo.Extra[key.Value] = extra
+		}
+	}
+	return topo, nil
+}
+
+func decodeList(value *yaml.Node, fn func(item *yaml.Node) error) error {
+	// an empty list can also be written as "nodes:" (null)
+	if value.Kind == yaml.ScalarNode && value.Tag == "!!null" {
+		return nil
+	}
+	if value.Kind != yaml.SequenceNode {
+		return fmt.Errorf("line %d: expected a list", value.Line)
+	}
+	for _, item := range value.Content {
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for idx := 0; idx+1 < len(node.Content); idx += 2 {
+		if node.Content[idx].Value == key {
+			return node.Content[idx+1]
+		}
+	}
+	return nil
+}
+
+// NodeByLabel returns the node with the given label or nil.
+func (t *Topology) NodeByLabel(label string) *Node {
+	for idx := range t.Nodes {
+		if t.Nodes[idx].Label == label {
+			return &t.Nodes[idx]
+		}
+	}
+	return nil
+}
+
+// HasPosition reports whether the node has x or y coordinates in the
+// topology.
+func (n *Node) HasPosition() bool {
+	_, x := n.Raw["x"]
+	_, y := n.Raw["y"]
+	return x || y
+}
+
+// InterfaceLabels returns the interface labels of the node ordered by slot,
+// interfaces without a slot (loopbacks) go last.
+func (n *Node) InterfaceLabels() []string {
+	ifaces := slices.Clone(n.Interfaces)
+	sort.SliceStable(ifaces, func(i, j int) bool {
+		a, b := ifaces[i].Slot, ifaces[j].Slot
+		switch {
+		case a == nil:
+			return false
+		case b == nil:
+			return true
+		}
+		return *a < *b
+	})
+	labels := make([]string, 0, len(ifaces))
+	for _, iface := range ifaces {
+		labels = append(labels, iface.Label)
+	}
+	return labels
+}
+
+// HasTag reports whether any node of the topology has the given tag.
+func (t *Topology) HasTag(tag string) bool {
+	for _, node := range t.Nodes {
+		for _, nodeTag := range node.Tags {
+			if nodeTag == tag {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,191 @@
+package topology
+
+import (
+	"testing"
+
+	"github.com/rschmied/gocmlclient/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTopology = `lab:
+  title: test
+  version: 0.1.0
+nodes:
+  - id: n0
+    label: r1
+    node_definition: iosv
+    tags: [core]
+    interfaces:
+      - id: i0
+        label: Loopback0
+        type: loopback
+      - id: i1
+        label: GigabitEthernet0/0
+        slot: 0
+        type: physical
+  - id: n1
+    label: r2
+    node_definition: iosv
+    image_definition: iosv-159-3
+    interfaces:
+      - id: i0
+        slot: 0
+        type: physical
+links:
+  - id: l0
+    n1: n0
+    n2: n1
+    i1: i1
+    i2: i0
+`
+
+func messages(problems []Problem) []string {
+	result := []string{}
+	for _, p := range problems {
+		result = append(result, p.String())
+	}
+	return result
+}
+
+func TestParse(t *testing.T) {
+	topo, err := Parse(testTopology)
+	require.NoError(t, err)
+
+	assert.Equal(t, "0.1.0", topo.Lab.Version)
+	assert.Len(t, topo.Nodes, 2)
+	assert.Len(t, topo.Links, 1)
+	assert.Equal(t, 5, topo.Nodes[0].Line)
+	assert.Equal(t, 13, topo.Nodes[0].Interfaces[1].Line)
+	assert.Equal(t, 26, topo.Links[0].Line)
+	assert.NotNil(t, topo.NodeByLabel("r2"))
+	assert.Nil(t, topo.NodeByLabel("r3"))
+	assert.True(t, topo.HasTag("core"))
+	assert.False(t, topo.HasTag("edge"))
+	assert.False(t, topo.Nodes[0].HasPosition())
+	assert.Empty(t, topo.Validate())
+
+	placed, err := Parse("nodes:\n  - label: r1\n    x: 0\n    y: 0\n")
+	require.NoError(t, err)
+	assert.True(t, placed.Nodes[0].HasPosition())
+
+	_, err = Parse("lab:\n  title: [\n")
+	assert.Error(t, err)
+
+	_, err = Parse("")
+	assert.Error(t, err)
+
+	_, err = Parse("nodes: bla\n")
+	assert.EqualError(t, err, "line 1: expected a list")
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		topo string
+		want []string
+	}{
+		{
+			name: "missing version",
+			topo: "lab:\n  title: x\nnodes: []\n",
+			want: []string{"line 1: lab version is missing"},
+		},
+		{
+			name: "invalid version",
+			topo: "lab:\n  version: one\n",
+			want: []string{`line 1: invalid lab version "one"`},
+		},
+		{
+			name: "unknown version",
+			topo: "lab:\n  version: 9.9.9\n",
+			want: []string{`line 1: unknown lab version "9.9.9"`},
+		},
+		{
+			name: "duplicate labels and IDs",
+			topo: `lab:
+  version: 0.1.0
+nodes:
+  - id: n0
+    label: r1
+    node_definition: iosv
+  - id: n0
+    label: r1
+    node_definition: iosv
+`,
+			want: []string{
+				`line 7: duplicate node ID "n0" (first defined on line 4)`,
+				`line 7: duplicate node label "r1" (first defined on line 4)`,
+			},
+		},
+		{
+			name: "dangling link endpoints",
+			topo: `lab:
+  version: 0.1.0
+nodes:
+  - id: n0
+    label: r1
+    node_definition: iosv
+    interfaces:
+      - id: i0
+        slot: 0
+links:
+  - id: l0
+    n1: n0
+    i1: i9
+    n2: n7
+    i2: i0
+`,
+			want: []string{
+				`line 11: link "l0" refers to unknown interface "i9" on node "r1"`,
+				`line 11: link "l0" refers to unknown node "n7"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topo, err := Parse(tt.topo)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, messages(topo.Validate()))
+		})
+	}
+}
+
+func TestValidateDefinitions(t *testing.T) {
+	topo, err := Parse(testTopology)
+	require.NoError(t, err)
+
+	iosv := models.NodeDefinition{ID: "iosv"}
+	images := []models.ImageDefinition{
+		{ID: "iosv-159-3", NodeDefID: "iosv"},
+		{ID: "csr-17", NodeDefID: "csr1000v"},
+	}
+	unchecked := "interface slots are not checked against the node definitions, their number of physical interfaces is not available"
+
+	assert.Equal(t, []string{unchecked}, messages(topo.ValidateDefinitions(models.NodeDefinitionMap{"iosv": iosv}, images)))
+
+	// no image definitions available, image check is skipped
+	assert.Equal(t, []string{unchecked}, messages(topo.ValidateDefinitions(models.NodeDefinitionMap{"iosv": iosv}, nil)))
+
+	assert.Equal(t,
+		[]string{
+			`line 5: unknown node definition "iosv" for node "r1"`,
+			`line 17: unknown node definition "iosv" for node "r2"`,
+		},
+		messages(topo.ValidateDefinitions(models.NodeDefinitionMap{}, images)),
+	)
+
+	topo.Nodes[1].ImageDefinition = "csr-17"
+	topo.Nodes[1].Interfaces[0].Slot = new(int)
+	*topo.Nodes[1].Interfaces[0].Slot = -1
+	problems := topo.ValidateDefinitions(models.NodeDefinitionMap{"iosv": iosv}, images)
+	assert.Equal(t,
+		[]string{
+			`line 17: image definition "csr-17" is for node definition "csr1000v", not "iosv" (node "r2")`,
+			`line 22: interface slot -1 of node "r2" is out of range`,
+			unchecked,
+		},
+		messages(problems),
+	)
+	assert.True(t, problems[2].Warning)
+}
@@ -0,0 +1,164 @@
+package topology
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/rschmied/gocmlclient/pkg/models"
+)
+
+// knownVersions are the topology schema versions known to the provider.
+// Other well-formed versions produce a warning as newer controllers might
+// support them.
+var knownVersions = []string{
+	"0.0.1", "0.0.2", "0.0.3", "0.0.4", "0.0.5",
+	"0.1.0", "0.2.0", "0.2.1", "0.2.2", "0.3.0",
+}
+
+var versionRE = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+
+// Problem is a validation finding, located at a line of the topology.
+type Problem struct {
+	Line    int
+	Message string
+	Warning bool
+}
+
+func (p Problem) String() string {
+	if p.Line == 0 {
+		return p.Message
+	}
+	return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+}
+
+func errorf(line int, format string, args ...any) Problem {
+	return Problem{Line: line, Message: fmt.Sprintf(format, args...)}
+}
+
+func warnf(line int, format string, args ...any) Problem {
+	return Problem{Line: line, Message: fmt.Sprintf(format, args...), Warning: true}
+}
+
+// Validate checks the structure of the topology: the schema version, unique
+// node labels and IDs and that link endpoints refer to existing nodes and
+// interfaces.
+func (t *Topology) Validate() []Problem {
+	problems := []Problem{}
+
+	switch {
+	case t.Lab.Version == "":
+		problems = append(problems, errorf(t.Lab.Line, "lab version is missing"))
+	case !versionRE.MatchString(t.Lab.Version):
+		problems = append(problems, errorf(t.Lab.Line, "invalid lab version %q", t.Lab.Version))
+	case !slices.Contains(knownVersions, t.Lab.Version):
+		problems = append(problems, warnf(t.Lab.Line, "unknown lab version %q", t.Lab.Version))
+	}
+
+	nodeIDs := map[string]*Node{}
+	labels := map[string]int{}
+	for idx := range t.Nodes {
+		node := &t.Nodes[idx]
+		if node.ID == "" {
+			problems = append(problems, errorf(node.Line, "node ID is missing"))
+		} else if first, ok := nodeIDs[node.ID]; ok {
+			problems = append(problems, errorf(node.Line, "duplicate node ID %q (first defined on line %d)", node.ID, first.Line))
+		} else {
+			nodeIDs[node.ID] = node
+		}
+		if node.Label == "" {
+			problems = append(problems, errorf(node.Line, "node label is missing"))
+		} else if first, ok := labels[node.Label]; ok {
+			problems = append(problems, errorf(node.Line, "duplicate node label %q (first defined on line %d)", node.Label, first))
+		} else {
+			labels[node.Label] = node.Line
+		}
+		if node.NodeDefinition == "" {
+			problems = append(problems, errorf(node.Line, "node definition of node %q is missing", node.Label))
+		}
+		ifaceIDs := map[string]int{}
+		for _, iface := range node.Interfaces {
+			if first, ok := ifaceIDs[iface.ID]; ok {
+				problems = append(problems, errorf(iface.Line, "duplicate interface ID %q on node %q (first defined on line %d)", iface.ID, node.Label, first))
+				continue
+			}
+			ifaceIDs[iface.ID] = iface.Line
+		}
+	}
+
+	endpoint := func(link *Link, nodeID, ifaceID string) *Problem {
+		node, ok := nodeIDs[nodeID]
+		if !ok {
+			p := errorf(link.Line, "link %q refers to unknown node %q", link.ID, nodeID)
+			return &p
+		}
+		for _, iface := range node.Interfaces {
+			if iface.ID == ifaceID {
+				return nil
+			}
+		}
+		p := errorf(link.Line, "link %q refers to unknown interface %q on node %q", link.ID, ifaceID, node.Label)
+		return &p
+	}
+
+	for idx := range t.Links {
+		link := &t.Links[idx]
+		if p := endpoint(link, link.N1, link.I1); p != nil {
+			problems = append(problems, *p)
+		}
+		if p := endpoint(link, link.N2, link.I2); p != nil {
+			problems = append(problems, *p)
+		}
+	}
+	return problems
+}
+
+// ValidateDefinitions checks the topology against the node and image
+// definitions of the controller: node and image definitions must exist and
+// image definitions must match the node definition. The node definitions
+// don't provide the number of physical interfaces, a warning points out that
+// the slot limits are not checked.
+func (t *Topology) ValidateDefinitions(nodeDefs models.NodeDefinitionMap, imageDefs []models.ImageDefinition) []Problem {
+	problems := []Problem{}
+	unchecked := false
+
+	images := make(map[string]models.ImageDefinition, len(imageDefs))
+	for _, imageDef := range imageDefs {
+		images[string(imageDef.ID)] = imageDef
+	}
+
+	for _, node := range t.Nodes {
+		if node.NodeDefinition == "" {
+			continue
+		}
+		if _, ok := nodeDefs[models.UUID(node.NodeDefinition)]; !ok {
+			problems = append(problems, errorf(node.Line, "unknown node definition %q for node %q", node.NodeDefinition, node.Label))
+			continue
+		}
+
+		if node.ImageDefinition != "" && imageDefs != nil {
+			imageDef, found := images[node.ImageDefinition]
+			switch {
+			case !found:
+				problems = append(problems, errorf(node.Line, "unknown image definition %q for node %q", node.ImageDefinition, node.Label))
+			case imageDef.NodeDefID != node.NodeDefinition:
+				problems = append(problems, errorf(node.Line, "image definition %q is for node definition %q, not %q (node %q)", node.ImageDefinition, imageDef.NodeDefID, node.NodeDefinition, node.Label))
+			}
+		}
+
+		for _, iface := range node.Interfaces {
+			if iface.Type == "loopback" || iface.Slot == nil {
+				continue
+			}
+			if *iface.Slot < 0 {
+				problems = append(problems, errorf(iface.Line, "interface slot %d of node %q is out of range", *iface.Slot, node.Label))
+				continue
+			}
+			unchecked = true
+		}
+	}
+	if unchecked {
+		problems = append(problems, warnf(0, "interface slots are not checked against the node definitions, their number of physical interfaces is not available"))
+	}
+	return problems
+}